@@ -0,0 +1,232 @@
+package cronfab
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrUnknownAlias     = errors.New("unknown alias")
+	ErrAliasName        = errors.New("alias names are '@' followed by letters, digits, '-' or '_'")
+	ErrAliasArgs        = errors.New("wrong number of alias arguments")
+	ErrAliasPlaceholder = errors.New("alias placeholders must be $1 to $9 without gaps")
+	ErrAliasCycle       = errors.New("alias refers to itself")
+	ErrAliasSpace       = errors.New("alias arguments must not contain whitespace")
+)
+
+// ErrorAlias is returned for an alias that can't be registered or expanded
+type ErrorAlias struct {
+	Name string
+	Err  error
+}
+
+func (e *ErrorAlias) Error() string {
+	return fmt.Sprintf("alias %s: %v", e.Name, e.Err)
+}
+
+// Unwrap return the reason the alias failed so that errors.Is(err, ErrAliasCycle) works
+func (e *ErrorAlias) Unwrap() error {
+	return e.Err
+}
+
+// RegisterAlias adds or replaces the alias name, like "@businesshours", for expr.  expr is a
+// crontab line or a call of another alias, and may use the arguments of a call, like
+// "@businesshours(9,17)", as $1 to $9.  Arguments are separated by commas or colons, so
+// "@at(14:30)" has two.  The alias is checked now rather than when it is used: placeholders are
+// filled with values that are in range for where they appear, the result must parse, aliases it
+// calls must exist and take as many arguments, and no alias may end up calling itself.  An argument
+// may not contain whitespace, and an expansion must have as many fields as the config, so that
+// arguments can't add fields.
+//
+// RegisterAlias changes the config's Aliases map and is not safe for concurrent use, nor while the
+// config is used elsewhere.  To add aliases to a shared config, like DefaultCrontabConfig, register
+// them on a Copy.
+func (cc *CrontabConfig) RegisterAlias(name, expr string) error {
+	if !validAliasName(name) {
+		return &ErrorAlias{Name: name, Err: ErrAliasName}
+	}
+	old, had := cc.Aliases[name]
+	if cc.Aliases == nil {
+		cc.Aliases = map[string]string{}
+	}
+	cc.Aliases[name] = expr
+	err := cc.ValidateAliases()
+	if err != nil {
+		if had {
+			cc.Aliases[name] = old
+		} else {
+			delete(cc.Aliases, name)
+		}
+	}
+	return err
+}
+
+// ValidateAliases checks every alias of the config as RegisterAlias does.  It is useful after
+// setting Aliases directly.
+func (cc *CrontabConfig) ValidateAliases() error {
+	names := make([]string, 0, len(cc.Aliases))
+	for name := range cc.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !validAliasName(name) {
+			return &ErrorAlias{Name: name, Err: ErrAliasName}
+		}
+		expr := cc.Aliases[name]
+		n, err := aliasArity(expr)
+		if err != nil {
+			return &ErrorAlias{Name: name, Err: err}
+		}
+		args := make([]string, n)
+		for i := range args {
+			args[i] = "$" + strconv.Itoa(i+1)
+		}
+		s, err := cc.expandAlias(name, args, map[string]bool{})
+		if err != nil {
+			return err
+		}
+		if _, err := cc.parseCronTab(cc.fillPlaceholders(s)); err != nil {
+			return &ErrorAlias{Name: name, Err: err}
+		}
+	}
+	return nil
+}
+
+// expandAlias return the crontab line an alias call expands to.  seen holds the aliases being
+// expanded, to find cycles.
+func (cc *CrontabConfig) expandAlias(name string, args []string, seen map[string]bool) (string, error) {
+	expr, ok := cc.Aliases[name]
+	if !ok {
+		return "", &ErrorAlias{Name: name, Err: ErrUnknownAlias}
+	}
+	if seen[name] {
+		return "", &ErrorAlias{Name: name, Err: ErrAliasCycle}
+	}
+	n, err := aliasArity(expr)
+	if err != nil {
+		return "", &ErrorAlias{Name: name, Err: err}
+	}
+	if len(args) != n {
+		return "", &ErrorAlias{Name: name, Err: ErrAliasArgs}
+	}
+	if n > 0 {
+		pairs := make([]string, 0, 2*n)
+		for i, arg := range args {
+			pairs = append(pairs, "$"+strconv.Itoa(i+1), arg)
+		}
+		// one pass, so arguments that are themselves placeholders are not replaced again
+		expr = strings.NewReplacer(pairs...).Replace(expr)
+	}
+	if !strings.HasPrefix(expr, "@") {
+		if len(strings.Fields(expr)) != len(cc.Fields) {
+			return "", &ErrorAlias{Name: name, Err: ErrFieldCount}
+		}
+		return expr, nil
+	}
+	seen[name] = true
+	next, nextArgs, err := splitAliasCall(expr)
+	if err != nil {
+		return "", &ErrorAlias{Name: name, Err: err}
+	}
+	return cc.expandAlias(next, nextArgs, seen)
+}
+
+// splitAliasCall splits "@name(a,b)" into its name and arguments
+func splitAliasCall(s string) (string, []string, error) {
+	i := strings.IndexByte(s, '(')
+	if i < 0 {
+		return s, nil, nil
+	}
+	if !strings.HasSuffix(s, ")") {
+		return "", nil, ErrAliasArgs
+	}
+	inner := s[i+1 : len(s)-1]
+	if strings.TrimSpace(inner) == "" {
+		return s[:i], nil, nil
+	}
+	args := strings.Split(strings.Replace(inner, ":", ",", -1), ",")
+	for i := range args {
+		args[i] = strings.TrimSpace(args[i])
+		if args[i] == "" {
+			return "", nil, ErrAliasArgs
+		}
+		if strings.IndexFunc(args[i], unicode.IsSpace) >= 0 {
+			return "", nil, ErrAliasSpace
+		}
+	}
+	return s[:i], args, nil
+}
+
+// aliasArity return the number of arguments an alias expression takes
+func aliasArity(expr string) (int, error) {
+	var used [10]bool
+	n := 0
+	for i := 0; i < len(expr); i++ {
+		if expr[i] != '$' {
+			continue
+		}
+		if i+1 == len(expr) || expr[i+1] < '1' || expr[i+1] > '9' {
+			return 0, ErrAliasPlaceholder
+		}
+		k := int(expr[i+1] - '0')
+		used[k] = true
+		if k > n {
+			n = k
+		}
+	}
+	for k := 1; k <= n; k++ {
+		if !used[k] {
+			return 0, ErrAliasPlaceholder
+		}
+	}
+	return n, nil
+}
+
+// fillPlaceholders replaces each placeholder left in a crontab line with a value that is valid
+// where it appears: the field's maximum at the end of a range, 1 as a step, the field's minimum
+// otherwise
+func (cc *CrontabConfig) fillPlaceholders(s string) string {
+	fields := strings.Fields(s)
+	for i, field := range fields {
+		if i >= len(cc.Fields) {
+			break
+		}
+		f := cc.Fields[i]
+		var sb strings.Builder
+		for j := 0; j < len(field); j++ {
+			if field[j] != '$' || j+1 == len(field) {
+				sb.WriteByte(field[j])
+				continue
+			}
+			switch {
+			case j > 0 && field[j-1] == '-':
+				sb.WriteString(strconv.Itoa(f.Max))
+			case j > 0 && field[j-1] == '/':
+				sb.WriteString("1")
+			default:
+				sb.WriteString(strconv.Itoa(f.Min))
+			}
+			j++
+		}
+		fields[i] = sb.String()
+	}
+	return strings.Join(fields, " ")
+}
+
+// validAliasName return true if name is '@' followed by letters, digits, '-' or '_'
+func validAliasName(name string) bool {
+	if len(name) < 2 || name[0] != '@' {
+		return false
+	}
+	for _, c := range name[1:] {
+		if c != '-' && c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
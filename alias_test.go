@@ -0,0 +1,175 @@
+package cronfab
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func aliasConfig(t *testing.T) *CrontabConfig {
+	t.Helper()
+	cc := MustCrontabConfig(DefaultCrontabConfig.Fields)
+	for _, a := range [][2]string{
+		{"@weekdays", "0 0 * * mon-fri"},
+		{"@businesshours", "0 $1-$2 * * mon-fri"},
+		{"@at", "$2 $1 * * *"},
+		{"@every-n-minutes", "*/$1 * * * *"},
+		{"@workday", "@businesshours(9,17)"},
+		{"@morning", "@businesshours(6,$1)"},
+	} {
+		if err := cc.RegisterAlias(a[0], a[1]); err != nil {
+			t.Fatalf("%s: %v", a[0], err)
+		}
+	}
+	return cc
+}
+
+func TestRegisterAlias_Expand(t *testing.T) {
+	cc := aliasConfig(t)
+	for _, tc := range []struct {
+		s    string
+		want string
+	}{
+		{"@weekdays", "0 0 * * mon-fri"},
+		{"@businesshours(9,17)", "0 9-17 * * mon-fri"},
+		{"@businesshours( 8 , 18 )", "0 8-18 * * mon-fri"},
+		{"@at(14:30)", "30 14 * * *"},
+		{"@every-n-minutes(15)", "*/15 * * * *"},
+		{"@workday", "0 9-17 * * mon-fri"},
+		{"@morning(11)", "0 6-11 * * mon-fri"},
+	} {
+		got, err := cc.ParseCronTab(tc.s)
+		if err != nil {
+			t.Errorf("%q: %v", tc.s, err)
+			continue
+		}
+		want, err := cc.ParseCronTab(tc.want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("%q: expected %v, got %v", tc.s, want, got)
+		}
+	}
+
+	ctl, err := cc.ParseCronTab("@at(14:30)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := cc.Next(ctl, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC); !n.Equal(want) {
+		t.Errorf("expected %v, got %v", want, n)
+	}
+}
+
+func TestRegisterAlias_UseErrors(t *testing.T) {
+	cc := aliasConfig(t)
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{"@bogus", ErrUnknownAlias},
+		{"@businesshours", ErrAliasArgs},
+		{"@businesshours(9)", ErrAliasArgs},
+		{"@weekdays(1)", ErrAliasArgs},
+		{"@at(14,,30)", ErrAliasArgs},
+		{"@at(14:30", ErrAliasArgs},
+		{"@at(14 1 1:30)", ErrAliasSpace},
+		{"@every-n-minutes(5\t*)", ErrAliasSpace},
+		{"@businesshours(9,25)", nil},
+	} {
+		_, err := cc.ParseCronTab(tc.s)
+		if err == nil {
+			t.Errorf("%q: expected an error", tc.s)
+			continue
+		}
+		if tc.err != nil && !errors.Is(err, tc.err) {
+			t.Errorf("%q: expected %v, got %v", tc.s, tc.err, err)
+		}
+	}
+}
+
+func TestRegisterAlias_Validation(t *testing.T) {
+	cc := aliasConfig(t)
+	for _, tc := range []struct {
+		name string
+		expr string
+		err  error
+	}{
+		{"daily", "0 0 * * *", ErrAliasName},
+		{"@two words", "0 0 * * *", ErrAliasName},
+		{"@gap", "$2 * * * *", ErrAliasPlaceholder},
+		{"@dollar", "$ * * * *", ErrAliasPlaceholder},
+		{"@missing", "@nowhere", ErrUnknownAlias},
+		{"@short", "@businesshours(9)", ErrAliasArgs},
+		{"@self", "@self", ErrAliasCycle},
+		{"@bad", "61 * * * *", nil},
+		{"@badarg", "0 $1 * * sunday-$2-x", nil},
+		{"@short-line", "0 0 *", ErrFieldCount},
+		{"@long-line", "0 0 * * * *", ErrFieldCount},
+		{"@spaced-call", "@businesshours(9, 1 17)", ErrAliasSpace},
+	} {
+		err := cc.RegisterAlias(tc.name, tc.expr)
+		var ea *ErrorAlias
+		if !errors.As(err, &ea) {
+			t.Errorf("%s: expected an alias error, got %v", tc.name, err)
+			continue
+		}
+		if tc.err != nil && !errors.Is(err, tc.err) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.err, err)
+		}
+		if _, ok := cc.Aliases[tc.name]; ok {
+			t.Errorf("%s: registered despite %v", tc.name, err)
+		}
+	}
+
+	// redefining an alias so that another alias calls it with the wrong arguments is refused, and
+	// the old definition kept
+	if err := cc.RegisterAlias("@businesshours", "0 $1 * * *"); !errors.Is(err, ErrAliasArgs) {
+		t.Errorf("expected %v, got %v", ErrAliasArgs, err)
+	}
+	if cc.Aliases["@businesshours"] != "0 $1-$2 * * mon-fri" {
+		t.Errorf("unexpected %q", cc.Aliases["@businesshours"])
+	}
+}
+
+func TestCrontabConfig_Copy(t *testing.T) {
+	cc := DefaultCrontabConfig.Copy()
+	if err := cc.RegisterAlias("@at", "$2 $1 * * *"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := DefaultCrontabConfig.Aliases["@at"]; ok {
+		t.Error("registering on the copy changed the original")
+	}
+	if cc.Aliases["@daily"] != DefaultCrontabConfig.Aliases["@daily"] || cc.Name != DefaultCrontabConfig.Name {
+		t.Errorf("unexpected copy %+v", cc)
+	}
+}
+
+func TestValidateAliases_Cycle(t *testing.T) {
+	cc := MustCrontabConfig(DefaultCrontabConfig.Fields)
+	cc.Aliases = map[string]string{
+		"@a": "@b",
+		"@b": "@c(1)",
+		"@c": "@a",
+	}
+	if err := cc.ValidateAliases(); !errors.Is(err, ErrAliasArgs) {
+		t.Errorf("expected %v, got %v", ErrAliasArgs, err)
+	}
+	cc.Aliases["@b"] = "@c"
+	if err := cc.ValidateAliases(); !errors.Is(err, ErrAliasCycle) {
+		t.Errorf("expected %v, got %v", ErrAliasCycle, err)
+	}
+	if _, err := cc.ParseCronTab("@a"); !errors.Is(err, ErrAliasCycle) {
+		t.Errorf("expected %v, got %v", ErrAliasCycle, err)
+	}
+	if err := DefaultCrontabConfig.ValidateAliases(); err != nil {
+		t.Errorf("built-in aliases: %v", err)
+	}
+	if err := SecondCrontabConfig.ValidateAliases(); err != nil {
+		t.Errorf("built-in aliases: %v", err)
+	}
+}
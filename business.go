@@ -0,0 +1,176 @@
+package cronfab
+
+import (
+	"time"
+)
+
+// maxBusinessGap is how many days in a row BusinessDayUnit looks through for a business day
+// before giving up, so that a calendar that excludes every day cannot hang a search
+const maxBusinessGap = 366
+
+// BusinessDayUnit units in business days: Monday to Friday, less the days Holidays excludes.  A
+// business day's period runs until the next business day, so the weekend and holidays after a
+// business day belong to it.
+type BusinessDayUnit struct {
+	// Holidays excludes days from the business days.  A day is excluded if the calendar excludes
+	// its start.  No holidays if nil.
+	Holidays Calendar
+}
+
+func (BusinessDayUnit) String() string {
+	return "business day"
+}
+
+// IsBusinessDay return true if t is on a weekday that is not a holiday
+func (u BusinessDayUnit) IsBusinessDay(t time.Time) bool {
+	switch t.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	return u.Holidays == nil || !u.Holidays.Excludes(DayUnit{}.Trunc(t))
+}
+
+// Add moves t to the business day at or after it, or at or before it if n is negative, and then
+// n business days on, keeping the time of day.  Add(t, 0) of a day that is not a business day is
+// the next business day.
+func (u BusinessDayUnit) Add(t time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	t = u.seek(t, step)
+	for ; n > 0; n-- {
+		t = u.seek(t.AddDate(0, 0, step), step)
+	}
+	return t
+}
+
+func (BusinessDayUnit) Less(u Unit) bool {
+	switch u.(type) {
+	case SecondUnit, MinuteUnit, HourUnit, DayUnit, BusinessDayUnit:
+		return false
+	}
+	return true
+}
+
+// Trunc return the start of the business day at or before t
+func (u BusinessDayUnit) Trunc(t time.Time) time.Time {
+	return u.seek(DayUnit{}.Trunc(t), -1)
+}
+
+// seek return the first business day from t, stepping step days at a time
+func (u BusinessDayUnit) seek(t time.Time, step int) time.Time {
+	for i := 0; i < maxBusinessGap && !u.IsBusinessDay(t); i++ {
+		t = t.AddDate(0, 0, step)
+	}
+	return t
+}
+
+// count return the number of business days of the month of t before the day of t, and the number
+// after it
+func (u BusinessDayUnit) count(t time.Time) (before, after int) {
+	last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	for d := 1; d <= last; d++ {
+		if d == t.Day() || !u.IsBusinessDay(time.Date(t.Year(), t.Month(), d, 0, 0, 0, 0, t.Location())) {
+			continue
+		}
+		if d < t.Day() {
+			before++
+		} else {
+			after++
+		}
+	}
+	return before, after
+}
+
+// NewBusinessDayField return a field of the business day of the month, counting from 1.  A day
+// that is not a business day has the index of the business day after it.
+func NewBusinessDayField(holidays Calendar) FieldConfig {
+	u := BusinessDayUnit{Holidays: holidays}
+	return FieldConfig{
+		Unit: u,
+		Name: "business day",
+		Min:  1,
+		Max:  23,
+		GetIndex: func(t time.Time) int {
+			before, _ := u.count(t)
+			return before + 1
+		},
+	}
+}
+
+// NewBusinessDayFromEndField return a field of the business day of the month counting back from
+// the last, which is 1.  A day that is not a business day has the index of the business day after
+// it, or 0 if the month has none.  It is a field of its own rather than negative values of the
+// business day field because the crontab grammar reads '-' as a range, so "-1" does not parse,
+// and a field has one index per time to search by.
+func NewBusinessDayFromEndField(holidays Calendar) FieldConfig {
+	u := BusinessDayUnit{Holidays: holidays}
+	return FieldConfig{
+		Unit: u,
+		Name: "business day from end",
+		Min:  1,
+		Max:  23,
+		GetIndex: func(t time.Time) int {
+			_, after := u.count(t)
+			if u.IsBusinessDay(t) {
+				return after + 1
+			}
+			return after
+		},
+	}
+}
+
+// BusinessCrontabConfig is like DefaultCrontabConfig with business days in place of days: minute,
+// hour, business day of month, business day from end of month and month.  "0 6 3 * *" is 06:00 on
+// the third business day of every month and "0 6 * 1 *" is 06:00 on the last.  Its business days
+// are Monday to Friday; NewBusinessCrontabConfig makes one with holidays.
+var BusinessCrontabConfig = NewBusinessCrontabConfig(nil)
+
+// NewBusinessCrontabConfig return a config like BusinessCrontabConfig whose business days leave
+// out the days holidays excludes
+func NewBusinessCrontabConfig(holidays Calendar) *CrontabConfig {
+	cc := MustCrontabConfig([]FieldConfig{
+		{
+			Unit: MinuteUnit{},
+			Name: "minute",
+			Min:  0,
+			Max:  59,
+			GetIndex: func(t time.Time) int {
+				return t.Minute()
+			},
+		},
+		{
+			Unit: HourUnit{},
+			Name: "hour",
+			Min:  0,
+			Max:  23,
+			GetIndex: func(t time.Time) int {
+				return t.Hour()
+			},
+		},
+		NewBusinessDayField(holidays),
+		NewBusinessDayFromEndField(holidays),
+		{
+			Unit:       MonthUnit{},
+			Name:       "month",
+			RangeNames: []string{"january", "february", "march", "april", "may", "june", "july", "august", "september", "october", "november", "december"},
+			Min:        1,
+			Max:        12,
+			GetIndex: func(t time.Time) int {
+				return int(t.Month())
+			},
+		},
+	})
+	cc.Name = "business"
+	cc.Aliases = map[string]string{
+		"@yearly":           "0 0 1 1 *",
+		"@annually":         "0 0 1 1 *",
+		"@monthly":          "0 0 1 * *",
+		"@firstbusinessday": "0 0 1 * *",
+		"@lastbusinessday":  "0 0 * 1 *",
+		"@daily":            "0 0 * * *",
+		"@hourly":           "0 * * * *",
+	}
+	return cc
+}
@@ -0,0 +1,108 @@
+package cronfab
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusinessDayUnit(t *testing.T) {
+	u := BusinessDayUnit{Holidays: NewHolidayCalendar(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))}
+	day := func(m time.Month, d, h int) time.Time {
+		return time.Date(2025, m, d, h, 0, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		t    time.Time
+		n    int
+		want time.Time
+	}{
+		{day(1, 3, 10), 1, day(1, 6, 10)},
+		{day(1, 6, 10), -1, day(1, 3, 10)},
+		{day(1, 4, 10), 0, day(1, 6, 10)},
+		{day(1, 4, 10), -1, day(1, 2, 10)},
+		{time.Date(2024, 12, 31, 10, 0, 0, 0, time.UTC), 1, day(1, 2, 10)},
+		{day(1, 6, 10), 5, day(1, 13, 10)},
+	} {
+		if got := u.Add(tc.t, tc.n); !got.Equal(tc.want) {
+			t.Errorf("Add(%v, %d): expected %v, got %v", tc.t, tc.n, tc.want, got)
+		}
+	}
+	if got, want := u.Trunc(day(1, 5, 13)), day(1, 3, 0); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got, want := u.Trunc(day(1, 1, 13)), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestBusinessCrontabConfig(t *testing.T) {
+	cc := BusinessCrontabConfig
+	want := []string{"minute", "hour", "business day", "month"}
+	for i, u := range cc.Units {
+		if u.String() != want[i] {
+			t.Errorf("%d: expected %s, got %s", i, want[i], u)
+		}
+	}
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 6, 0, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		line  string
+		start time.Time
+		want  []time.Time
+	}{
+		// February and March 2025 both start on a Saturday
+		{"0 6 3 * *", at(2025, 1, 31), []time.Time{at(2025, 2, 5), at(2025, 3, 5), at(2025, 4, 3)}},
+		{"0 6 * 1 *", at(2025, 1, 1), []time.Time{at(2025, 1, 31), at(2025, 2, 28), at(2025, 3, 31)}},
+		// August 2025 ends on a weekend
+		{"0 6 * 1 *", at(2025, 8, 1), []time.Time{at(2025, 8, 29), at(2025, 9, 30)}},
+		{"0 6 * 2 *", at(2025, 1, 1), []time.Time{at(2025, 1, 30), at(2025, 2, 27)}},
+		{"@firstbusinessday", at(2025, 5, 20), []time.Time{at(2025, 6, 2).Add(-6 * time.Hour), at(2025, 7, 1).Add(-6 * time.Hour)}},
+		{"0 6 1 * *", at(2025, 1, 30), []time.Time{at(2025, 2, 3)}},
+	} {
+		cl, err := cc.Compile(mustLine(t, cc, tc.line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := collect(t, cl, tc.start, len(tc.want))
+		for i := range tc.want {
+			if !got[i].Equal(tc.want[i]) {
+				t.Errorf("%q %d: expected %v, got %v", tc.line, i, tc.want[i], got[i])
+			}
+		}
+	}
+
+	cl, err := cc.Compile(mustLine(t, cc, "@daily"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cl.Matches(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) || !cl.Matches(time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected Matches")
+	}
+}
+
+func TestNewBusinessCrontabConfig_Holidays(t *testing.T) {
+	cc := NewBusinessCrontabConfig(NewHolidayCalendar(
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+	))
+	at := func(m time.Month, d int) time.Time {
+		return time.Date(2025, m, d, 6, 0, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		line  string
+		start time.Time
+		want  time.Time
+	}{
+		{"0 6 3 * jan", time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC), at(1, 6)},
+		{"0 6 1 * jan", time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC), at(1, 2)},
+		{"0 6 * 1 mar", at(1, 1), at(3, 28)},
+	} {
+		n, err := cc.Next(mustLine(t, cc, tc.line), tc.start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !n.Equal(tc.want) {
+			t.Errorf("%q: expected %v, got %v", tc.line, tc.want, n)
+		}
+	}
+}
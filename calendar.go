@@ -0,0 +1,380 @@
+package cronfab
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAlwaysExcluded = errors.New("calendars exclude every remaining time")
+	ErrBadWindow      = errors.New("time of day window must be within a day")
+	ErrBadDateRange   = errors.New("date range must end after it starts")
+)
+
+// Calendar excludes instants from a schedule, like bank holidays or a change freeze
+type Calendar interface {
+	// Excludes return true if the calendar excludes t
+	Excludes(t time.Time) bool
+	// NextIncluded return the first time at or after t that the calendar does not exclude, or the
+	// zero time if there is none
+	NextIncluded(t time.Time) time.Time
+}
+
+// Excluded is a schedule with the times its calendars exclude left out
+type Excluded struct {
+	Schedule  Schedule
+	Calendars []Calendar
+	// MaxIt is the number of excluded times Next may skip before giving up.  DefaultMaxIt if
+	// zero.
+	MaxIt int
+}
+
+// Exclude return s without the times any of the calendars exclude
+func Exclude(s Schedule, calendars ...Calendar) *Excluded {
+	return &Excluded{Schedule: s, Calendars: calendars}
+}
+
+// Next return the next time after t that the schedule fires and no calendar excludes.  Excluded
+// stretches are skipped whole: the schedule is searched again from where the calendars next
+// include.
+func (e *Excluded) Next(t time.Time) (time.Time, error) {
+	maxIt := e.MaxIt
+	if maxIt <= 0 {
+		maxIt = DefaultMaxIt
+	}
+	n, err := e.Schedule.Next(t)
+	for j := 0; err == nil; j++ {
+		resume := time.Time{}
+		for _, c := range e.Calendars {
+			if !c.Excludes(n) {
+				continue
+			}
+			r := c.NextIncluded(n)
+			if r.IsZero() {
+				return n, ErrAlwaysExcluded
+			}
+			if r.After(resume) {
+				resume = r.In(t.Location())
+			}
+		}
+		if resume.IsZero() {
+			return n, nil
+		}
+		if j >= maxIt {
+			return n, &ErrorSearchLimit{Err: ErrMaxit, Start: t, Reached: n, Iterations: j}
+		}
+		// the first fire time at or after resume
+		n, err = e.Schedule.Next(resume.Add(-time.Nanosecond))
+	}
+	return n, err
+}
+
+// Matches return true if the schedule fires at t and no calendar excludes it
+func (e *Excluded) Matches(t time.Time) bool {
+	if !matches(e.Schedule, t) {
+		return false
+	}
+	for _, c := range e.Calendars {
+		if c.Excludes(t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Excluded) String() string {
+	if str, ok := e.Schedule.(fmt.Stringer); ok {
+		return str.String()
+	}
+	return fmt.Sprintf("%T", e.Schedule)
+}
+
+// dateKey identifies a calendar date
+func dateKey(y int, m time.Month, d int) int {
+	return y*10000 + int(m)*100 + d
+}
+
+// inLocation return t in loc, or t if loc is nil
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return t
+	}
+	return t.In(loc)
+}
+
+// nextMidnight return the start of the day after the day of t
+func nextMidnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+}
+
+// HolidayCalendar excludes whole days
+type HolidayCalendar struct {
+	// Location is where days are reckoned.  The location of the time being checked if nil.
+	Location *time.Location
+	dates    map[int]bool
+}
+
+// NewHolidayCalendar return a calendar that excludes the dates of the given times, each taken in
+// its own location
+func NewHolidayCalendar(dates ...time.Time) *HolidayCalendar {
+	hc := &HolidayCalendar{dates: map[int]bool{}}
+	for _, d := range dates {
+		hc.Add(d)
+	}
+	return hc
+}
+
+// Add excludes the date of d
+func (hc *HolidayCalendar) Add(d time.Time) {
+	hc.dates[dateKey(d.Date())] = true
+}
+
+// Excludes return true if t is on one of the dates
+func (hc *HolidayCalendar) Excludes(t time.Time) bool {
+	return hc.dates[dateKey(inLocation(t, hc.Location).Date())]
+}
+
+// NextIncluded return the start of the first day at or after t that is not a holiday, or t
+func (hc *HolidayCalendar) NextIncluded(t time.Time) time.Time {
+	t = inLocation(t, hc.Location)
+	for i := 0; hc.Excludes(t); i++ {
+		if i > len(hc.dates) {
+			return time.Time{}
+		}
+		t = nextMidnight(t)
+	}
+	return t
+}
+
+// AnnualDate is a day of the year, like December 25
+type AnnualDate struct {
+	Month time.Month
+	Day   int
+}
+
+// AnnualCalendar excludes the same days every year
+type AnnualCalendar struct {
+	// Location is where days are reckoned.  The location of the time being checked if nil.
+	Location *time.Location
+	days     map[AnnualDate]bool
+}
+
+// NewAnnualCalendar return a calendar that excludes the days every year
+func NewAnnualCalendar(days ...AnnualDate) *AnnualCalendar {
+	ac := &AnnualCalendar{days: map[AnnualDate]bool{}}
+	for _, d := range days {
+		ac.days[d] = true
+	}
+	return ac
+}
+
+// Excludes return true if t is on one of the days
+func (ac *AnnualCalendar) Excludes(t time.Time) bool {
+	_, m, d := inLocation(t, ac.Location).Date()
+	return ac.days[AnnualDate{Month: m, Day: d}]
+}
+
+// NextIncluded return the start of the first day at or after t that is not excluded, or t
+func (ac *AnnualCalendar) NextIncluded(t time.Time) time.Time {
+	t = inLocation(t, ac.Location)
+	for i := 0; ac.Excludes(t); i++ {
+		if i > 366 {
+			return time.Time{}
+		}
+		t = nextMidnight(t)
+	}
+	return t
+}
+
+// DailyCalendar excludes a window of the same times every day, like 22:00 to 06:00
+type DailyCalendar struct {
+	// Start and End are the window, as times since midnight.  The window includes Start and
+	// excludes End, and crosses midnight if End is before Start.
+	Start, End time.Duration
+	// Location is where times of day are reckoned.  The location of the time being checked if
+	// nil.
+	Location *time.Location
+}
+
+// NewDailyCalendar return a calendar that excludes the times of day from start up to end
+func NewDailyCalendar(start, end time.Duration) (*DailyCalendar, error) {
+	if start < 0 || start >= 24*time.Hour || end < 0 || end > 24*time.Hour || start == end {
+		return nil, ErrBadWindow
+	}
+	return &DailyCalendar{Start: start, End: end}, nil
+}
+
+// sinceMidnight return the wall clock time of t since midnight
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// atTime return the wall clock time d since midnight on the day of t
+func atTime(t time.Time, d time.Duration) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, int(d), t.Location())
+}
+
+// Excludes return true if the time of day of t is in the window
+func (dc *DailyCalendar) Excludes(t time.Time) bool {
+	d := sinceMidnight(inLocation(t, dc.Location))
+	if dc.Start < dc.End {
+		return d >= dc.Start && d < dc.End
+	}
+	return d >= dc.Start || d < dc.End
+}
+
+// NextIncluded return the end of the window if t is in it, or t
+func (dc *DailyCalendar) NextIncluded(t time.Time) time.Time {
+	t = inLocation(t, dc.Location)
+	if !dc.Excludes(t) {
+		return t
+	}
+	if dc.Start > dc.End && sinceMidnight(t) >= dc.Start {
+		// the window ends tomorrow
+		return atTime(nextMidnight(t), dc.End)
+	}
+	return atTime(t, dc.End)
+}
+
+// DateRange is a stretch of time from Start up to End
+type DateRange struct {
+	Start, End time.Time
+}
+
+// RangeCalendar excludes stretches of time, like a change freeze
+type RangeCalendar struct {
+	ranges []DateRange
+}
+
+// NewRangeCalendar return a calendar that excludes the ranges
+func NewRangeCalendar(ranges ...DateRange) (*RangeCalendar, error) {
+	rc := &RangeCalendar{}
+	for _, r := range ranges {
+		if !r.End.After(r.Start) {
+			return nil, ErrBadDateRange
+		}
+		rc.ranges = append(rc.ranges, r)
+	}
+	sort.Slice(rc.ranges, func(i, j int) bool {
+		return rc.ranges[i].Start.Before(rc.ranges[j].Start)
+	})
+	return rc, nil
+}
+
+// Excludes return true if t is in one of the ranges
+func (rc *RangeCalendar) Excludes(t time.Time) bool {
+	for _, r := range rc.ranges {
+		if !t.Before(r.Start) && t.Before(r.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextIncluded return the end of the ranges t is in, or t
+func (rc *RangeCalendar) NextIncluded(t time.Time) time.Time {
+	for _, r := range rc.ranges {
+		// ranges are sorted by start, so one that starts after t can only follow another
+		if !t.Before(r.Start) && t.Before(r.End) {
+			t = r.End
+		}
+	}
+	return t
+}
+
+// ParseDateRange parses an iCalendar period, "start/end" or "start/duration", or a single date,
+// which is that whole day.  Times are DATE values like "20241225", or DATE-TIME values like
+// "20241220T170000" or "20241220T170000Z".  Durations are like "P2W", "P1D" or "PT8H30M".  Values
+// without a "Z" are in loc.
+func ParseDateRange(s string, loc *time.Location) (DateRange, error) {
+	i := strings.IndexByte(s, '/')
+	if i < 0 {
+		start, err := time.ParseInLocation("20060102", s, loc)
+		if err != nil {
+			return DateRange{}, err
+		}
+		return DateRange{Start: start, End: start.AddDate(0, 0, 1)}, nil
+	}
+	start, err := parseICalTime(s[:i], loc)
+	if err != nil {
+		return DateRange{}, err
+	}
+	var end time.Time
+	if strings.HasPrefix(s[i+1:], "P") {
+		end, err = addICalDuration(start, s[i+1:])
+	} else {
+		end, err = parseICalTime(s[i+1:], loc)
+	}
+	if err != nil {
+		return DateRange{}, err
+	}
+	if !end.After(start) {
+		return DateRange{}, ErrBadDateRange
+	}
+	return DateRange{Start: start, End: end}, nil
+}
+
+// parseICalTime parses an iCalendar DATE or DATE-TIME value
+func parseICalTime(s string, loc *time.Location) (time.Time, error) {
+	switch {
+	case strings.HasSuffix(s, "Z"):
+		return time.Parse("20060102T150405Z", s)
+	case strings.Contains(s, "T"):
+		return time.ParseInLocation("20060102T150405", s, loc)
+	}
+	return time.ParseInLocation("20060102", s, loc)
+}
+
+// addICalDuration adds an iCalendar duration to t.  Weeks and days are calendar days.
+func addICalDuration(t time.Time, s string) (time.Time, error) {
+	bad := fmt.Errorf("invalid duration %q", s)
+	rest := s[1:]
+	inTime := false
+	days := 0
+	var d time.Duration
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return t, bad
+			}
+			inTime = true
+			rest = rest[1:]
+			continue
+		}
+		j := 0
+		for j < len(rest) && rest[j] >= '0' && rest[j] <= '9' {
+			j++
+		}
+		if j == 0 || j == len(rest) {
+			return t, bad
+		}
+		v, err := strconv.Atoi(rest[:j])
+		if err != nil {
+			return t, bad
+		}
+		switch unit := rest[j]; {
+		case unit == 'W' && !inTime:
+			days += 7 * v
+		case unit == 'D' && !inTime:
+			days += v
+		case unit == 'H' && inTime:
+			d += time.Duration(v) * time.Hour
+		case unit == 'M' && inTime:
+			d += time.Duration(v) * time.Minute
+		case unit == 'S' && inTime:
+			d += time.Duration(v) * time.Second
+		default:
+			return t, bad
+		}
+		rest = rest[j+1:]
+	}
+	if (days == 0 && d == 0) || (inTime && d == 0) {
+		return t, bad
+	}
+	return t.AddDate(0, 0, days).Add(d), nil
+}
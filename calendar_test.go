@@ -0,0 +1,196 @@
+package cronfab
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExclude_Holidays(t *testing.T) {
+	cc := DefaultCrontabConfig
+	cl, err := cc.Compile(mustLine(t, cc, "0 9 * * mon-fri"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	holidays := NewHolidayCalendar(
+		time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC),
+	)
+	annual := NewAnnualCalendar(AnnualDate{Month: time.January, Day: 1})
+	s := Exclude(cl, holidays, annual)
+	got := collect(t, s, time.Date(2024, 12, 23, 12, 0, 0, 0, time.UTC), 4)
+	want := []time.Time{
+		time.Date(2024, 12, 24, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 12, 27, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 12, 30, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 12, 31, 9, 0, 0, 0, time.UTC),
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("%d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+	n, err := s.Next(want[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC); !n.Equal(w) {
+		t.Errorf("expected %v, got %v", w, n)
+	}
+	if s.Matches(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)) || !s.Matches(n) {
+		t.Error("unexpected Matches")
+	}
+}
+
+func TestExclude_Location(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*3600)
+	holidays := NewHolidayCalendar(time.Date(2024, 5, 3, 0, 0, 0, 0, tokyo))
+	holidays.Location = tokyo
+	hourly, err := DefaultCrontabConfig.Compile(mustLine(t, DefaultCrontabConfig, "0 * * * *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// May 3 in Tokyo is 15:00 UTC on May 2 to 15:00 UTC on May 3
+	n, err := Exclude(hourly, holidays).Next(time.Date(2024, 5, 2, 14, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 5, 3, 15, 0, 0, 0, time.UTC); !n.Equal(want) || n.Location() != time.UTC {
+		t.Errorf("expected %v, got %v", want, n)
+	}
+}
+
+func TestDailyCalendar(t *testing.T) {
+	if _, err := NewDailyCalendar(time.Hour, time.Hour); !errors.Is(err, ErrBadWindow) {
+		t.Errorf("expected %v, got %v", ErrBadWindow, err)
+	}
+	if _, err := NewDailyCalendar(0, 25*time.Hour); !errors.Is(err, ErrBadWindow) {
+		t.Errorf("expected %v, got %v", ErrBadWindow, err)
+	}
+	night, err := NewDailyCalendar(22*time.Hour, 6*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lunch, err := NewDailyCalendar(12*time.Hour, 13*time.Hour+30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(h, m int) time.Time {
+		return time.Date(2024, 3, 1, h, m, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		c    *DailyCalendar
+		t    time.Time
+		want time.Time
+	}{
+		{night, day(21, 59), day(21, 59)},
+		{night, day(22, 0), time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC)},
+		{night, day(5, 0), day(6, 0)},
+		{night, day(6, 0), day(6, 0)},
+		{lunch, day(12, 15), day(13, 30)},
+		{lunch, day(13, 30), day(13, 30)},
+	} {
+		if got := tc.c.NextIncluded(tc.t); !got.Equal(tc.want) {
+			t.Errorf("%v: expected %v, got %v", tc.t, tc.want, got)
+		}
+		if got := tc.c.Excludes(tc.t); got != !tc.want.Equal(tc.t) {
+			t.Errorf("%v: unexpected Excludes %v", tc.t, got)
+		}
+	}
+
+	every, err := NewIntervalSchedule(45*time.Minute, day(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := Exclude(every, night).Next(day(21, 50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 22:30 and later are excluded until 06:00, and the first 45 minute mark after that is 06:00
+	if want := time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC); !n.Equal(want) {
+		t.Errorf("expected %v, got %v", want, n)
+	}
+}
+
+func TestRangeCalendar(t *testing.T) {
+	freeze, err := ParseDateRange("20241220/20250102", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outage, err := ParseDateRange("20250102T000000Z/PT12H", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := NewRangeCalendar(outage, freeze)
+	if err != nil {
+		t.Fatal(err)
+	}
+	daily, err := DefaultCrontabConfig.Compile(mustLine(t, DefaultCrontabConfig, "0 6,18 * * *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := Exclude(daily, rc).Next(time.Date(2024, 12, 19, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 12, 19, 18, 0, 0, 0, time.UTC); !n.Equal(want) {
+		t.Errorf("expected %v, got %v", want, n)
+	}
+	n, err = Exclude(daily, rc).Next(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 1, 2, 18, 0, 0, 0, time.UTC); !n.Equal(want) {
+		t.Errorf("expected %v, got %v", want, n)
+	}
+
+	if _, err := NewRangeCalendar(DateRange{Start: n, End: n}); !errors.Is(err, ErrBadDateRange) {
+		t.Errorf("expected %v, got %v", ErrBadDateRange, err)
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		s          string
+		start, end time.Time
+	}{
+		{"20241225", time.Date(2024, 12, 25, 0, 0, 0, 0, berlin), time.Date(2024, 12, 26, 0, 0, 0, 0, berlin)},
+		{"20241220T170000/20241221T090000", time.Date(2024, 12, 20, 17, 0, 0, 0, berlin), time.Date(2024, 12, 21, 9, 0, 0, 0, berlin)},
+		{"20241220T170000Z/P1DT2H30M", time.Date(2024, 12, 20, 17, 0, 0, 0, time.UTC), time.Date(2024, 12, 21, 19, 30, 0, 0, time.UTC)},
+		{"20240325/P2W", time.Date(2024, 3, 25, 0, 0, 0, 0, berlin), time.Date(2024, 4, 8, 0, 0, 0, 0, berlin)},
+	} {
+		r, err := ParseDateRange(tc.s, berlin)
+		if err != nil {
+			t.Errorf("%q: %v", tc.s, err)
+			continue
+		}
+		if !r.Start.Equal(tc.start) || !r.End.Equal(tc.end) {
+			t.Errorf("%q: expected %v to %v, got %v to %v", tc.s, tc.start, tc.end, r.Start, r.End)
+		}
+	}
+	for _, s := range []string{"", "2024-12-25", "20241225/20241224", "20241225/P", "20241225/P1H", "20241225/PT1D", "20241225/P1DT", "20241225/PTT1H"} {
+		if _, err := ParseDateRange(s, berlin); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestExclude_Always(t *testing.T) {
+	var days []AnnualDate
+	for m := time.January; m <= time.December; m++ {
+		for d := 1; d <= 31; d++ {
+			days = append(days, AnnualDate{Month: m, Day: d})
+		}
+	}
+	daily, err := DefaultCrontabConfig.Compile(mustLine(t, DefaultCrontabConfig, "0 0 * * *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Exclude(daily, NewAnnualCalendar(days...)).Next(time.Now()); !errors.Is(err, ErrAlwaysExcluded) {
+		t.Errorf("expected %v, got %v", ErrAlwaysExcluded, err)
+	}
+}
@@ -0,0 +1,133 @@
+package cronfab
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultMissedLimit is the number of missed fire times Missed return when no limit is given
+const DefaultMissedLimit = 1000
+
+// Missed return the fire times of s after last and at or before now, in order, at most limit of
+// them.  more is true if there were more fire times than the limit.  A limit less than 1 means
+// DefaultMissedLimit.
+func Missed(s Schedule, last, now time.Time, limit int) (missed []time.Time, more bool, err error) {
+	if limit < 1 {
+		limit = DefaultMissedLimit
+	}
+	t := last
+	for {
+		t, err = s.Next(t)
+		if err != nil {
+			return missed, false, err
+		}
+		if t.After(now) {
+			return missed, false, nil
+		}
+		if len(missed) == limit {
+			return missed, true, nil
+		}
+		missed = append(missed, t)
+	}
+}
+
+// Missed return the fire times of the crontab line after last and at or before now.  See Missed.
+func (cc *CrontabConfig) Missed(ctl CrontabLine, last, now time.Time, limit int) ([]time.Time, bool, error) {
+	cl, err := cc.Compile(ctl)
+	if err != nil {
+		return nil, false, err
+	}
+	return Missed(cl, last, now, limit)
+}
+
+// LastMissed return the latest fire time of s after last and at or before now, and false if there
+// is none.  Unlike Missed it has no limit: it searches back from now, doubling the distance, until
+// it finds a fire time, and only steps through the fire times from there.
+func LastMissed(s Schedule, last, now time.Time) (time.Time, bool, error) {
+	span := now.Sub(last)
+	if span <= 0 {
+		return time.Time{}, false, nil
+	}
+	var from time.Time
+	for d := time.Second; ; {
+		from = last
+		if d < span {
+			from = now.Add(-d)
+		}
+		t, err := s.Next(from)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if !t.After(now) {
+			break
+		}
+		if from.Equal(last) {
+			return time.Time{}, false, nil
+		}
+		if d > span/2 {
+			d = span
+		} else {
+			d *= 2
+		}
+	}
+	var latest time.Time
+	for t := from; ; latest = t {
+		var err error
+		t, err = s.Next(t)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if t.After(now) {
+			return latest, true, nil
+		}
+	}
+}
+
+// LastMissed return the latest fire time of the crontab line after last and at or before now.  See
+// LastMissed.
+func (cc *CrontabConfig) LastMissed(ctl CrontabLine, last, now time.Time) (time.Time, bool, error) {
+	cl, err := cc.Compile(ctl)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return LastMissed(cl, last, now)
+}
+
+// CatchUpPolicy decides which missed fire times to run after downtime
+type CatchUpPolicy int
+
+const (
+	// CatchUpSkip runs none of the missed fire times and waits for the next one
+	CatchUpSkip CatchUpPolicy = iota
+	// CatchUpOnce runs once, for the latest missed fire time
+	CatchUpOnce
+	// CatchUpAll runs every missed fire time
+	CatchUpAll
+)
+
+func (p CatchUpPolicy) String() string {
+	switch p {
+	case CatchUpSkip:
+		return "skip"
+	case CatchUpOnce:
+		return "once"
+	case CatchUpAll:
+		return "all"
+	}
+	return fmt.Sprintf("CatchUpPolicy(%d)", int(p))
+}
+
+// Apply return the missed fire times to run under the policy.  CatchUpOnce picks the last of missed,
+// which is not the latest missed fire time if Missed was capped; LastMissed finds that one.
+func (p CatchUpPolicy) Apply(missed []time.Time) []time.Time {
+	if len(missed) == 0 {
+		return nil
+	}
+	switch p {
+	case CatchUpOnce:
+		return missed[len(missed)-1:]
+	case CatchUpAll:
+		return missed
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package cronfab
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMissed(t *testing.T) {
+	cc := DefaultCrontabConfig
+	ctl, err := cc.ParseCronTab("*/5 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := last.Add(20 * time.Minute)
+
+	missed, more, err := cc.Missed(ctl, last, now, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if more {
+		t.Errorf("expected no more")
+	}
+	if len(missed) != 4 {
+		t.Fatalf("expected 4 missed, got %v", missed)
+	}
+	for i, m := range missed {
+		if want := last.Add(time.Duration(i+1) * 5 * time.Minute); !m.Equal(want) {
+			t.Errorf("missed[%d]: expected %v, got %v", i, want, m)
+		}
+	}
+
+	missed, more, err = cc.Missed(ctl, last, now, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !more || len(missed) != 3 {
+		t.Errorf("expected 3 missed and more, got %v, %v", missed, more)
+	}
+
+	missed, more, err = cc.Missed(ctl, last, last.Add(4*time.Minute), 0)
+	if err != nil || more || len(missed) != 0 {
+		t.Errorf("expected nothing missed, got %v, %v, %v", missed, more, err)
+	}
+}
+
+func TestLastMissed(t *testing.T) {
+	cc := DefaultCrontabConfig
+	ctl, err := cc.ParseCronTab("*/5 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		now  time.Time
+		want time.Time
+		ok   bool
+	}{
+		{last.Add(20 * time.Minute), last.Add(20 * time.Minute), true},
+		{last.Add(23 * time.Minute), last.Add(20 * time.Minute), true},
+		{last.Add(4 * time.Minute), time.Time{}, false},
+		{last, time.Time{}, false},
+		// far more than DefaultMissedLimit fire times
+		{last.AddDate(1, 0, 0).Add(7 * time.Minute), last.AddDate(1, 0, 0).Add(5 * time.Minute), true},
+	} {
+		got, ok, err := cc.LastMissed(ctl, last, tc.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != tc.ok || !got.Equal(tc.want) {
+			t.Errorf("%v: expected %v %t, got %v %t", tc.now, tc.want, tc.ok, got, ok)
+		}
+	}
+
+	// a yearly line searched back from just after its fire time
+	ctl, err = cc.ParseCronTab("0 0 1 1 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := cc.LastMissed(ctl, last, time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC); err != nil || !ok || !got.Equal(want) {
+		t.Errorf("expected %v, got %v %t %v", want, got, ok, err)
+	}
+}
+
+func TestMissed_Errors(t *testing.T) {
+	cc := DefaultCrontabConfig
+	if _, _, err := cc.Missed(CrontabLine{}, time.Time{}, time.Time{}, 0); !errors.Is(err, ErrFieldCount) {
+		t.Errorf("expected %v, got %v", ErrFieldCount, err)
+	}
+	ctl, err := cc.ParseCronTab("0 0 30 feb *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if _, _, err := cc.Missed(ctl, last, last.AddDate(1, 0, 0), 0); !errors.Is(err, ErrMaxit) {
+		t.Errorf("expected %v, got %v", ErrMaxit, err)
+	}
+}
+
+func TestCatchUpPolicy_Apply(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	missed := []time.Time{t0, t0.Add(time.Minute), t0.Add(2 * time.Minute)}
+	if got := CatchUpSkip.Apply(missed); len(got) != 0 {
+		t.Errorf("skip: expected nothing, got %v", got)
+	}
+	if got := CatchUpOnce.Apply(missed); len(got) != 1 || !got[0].Equal(missed[2]) {
+		t.Errorf("once: expected %v, got %v", missed[2:], got)
+	}
+	if got := CatchUpAll.Apply(missed); len(got) != 3 {
+		t.Errorf("all: expected %v, got %v", missed, got)
+	}
+	if got := CatchUpOnce.Apply(nil); len(got) != 0 {
+		t.Errorf("once: expected nothing, got %v", got)
+	}
+}
+
+func TestCatchUpPolicy_String(t *testing.T) {
+	for p, want := range map[CatchUpPolicy]string{
+		CatchUpSkip:      "skip",
+		CatchUpOnce:      "once",
+		CatchUpAll:       "all",
+		CatchUpPolicy(7): "CatchUpPolicy(7)",
+	} {
+		if got := p.String(); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
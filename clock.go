@@ -0,0 +1,50 @@
+package cronfab
+
+import (
+	"time"
+)
+
+// Clock is the source of time for the parts of cronfab that wait for fire times.
+// RealClock uses the time package; cronfabtest.FakeClock is advanced by hand.
+type Clock interface {
+	// Now return the current time
+	Now() time.Time
+	// NewTimer return a timer that sends the current time on its channel after d
+	NewTimer(d time.Duration) Timer
+	// AfterFunc return a timer that calls f after d
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a Clock.  It behaves like *time.Timer.
+type Timer interface {
+	// C return the channel the time is sent on.  Nil for timers made by AfterFunc.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing.  It return false if the timer already fired or was stopped.
+	Stop() bool
+	// Reset changes the timer to fire after d.  It return true if the timer was active.
+	Reset(d time.Duration) bool
+}
+
+// RealClock is the Clock backed by the time package
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (RealClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+// realTimer adapts *time.Timer to Timer
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
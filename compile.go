@@ -0,0 +1,255 @@
+package cronfab
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+	"time"
+)
+
+var (
+	ErrEmptyField = errors.New("field allows no values")
+)
+
+// FieldSet is a compiled crontab field: a bitset of the values, offset from the field's minimum,
+// that the field allows.  Membership and next-value lookups take constant time per 64 values.
+type FieldSet struct {
+	min   int
+	first int
+	words []uint64
+}
+
+// NewFieldSet compiles the crontab field for a field config ranging from min to max.
+// Values outside the range are dropped.
+func NewFieldSet(min, max int, cf CrontabField) FieldSet {
+	return newFieldSet(min, max, cf, make([]uint64, fieldSetWords(min, max)))
+}
+
+// fieldSetWords return the number of words a set ranging from min to max needs
+func fieldSetWords(min, max int) int {
+	return (max-min)/64 + 1
+}
+
+// newFieldSet compiles the crontab field into the supplied zeroed words
+func newFieldSet(min, max int, cf CrontabField, words []uint64) FieldSet {
+	fs := FieldSet{
+		min:   min,
+		first: -1,
+		words: words,
+	}
+	for i := range cf {
+		c := cf.GetConstraint(i)
+		x := c.GetMin()
+		if x < min {
+			// start at the first value of the constraint that is in range
+			x += ((min - x + c.GetStep() - 1) / c.GetStep()) * c.GetStep()
+		}
+		for ; x <= c.GetMax() && x <= max; x += c.GetStep() {
+			k := x - min
+			fs.words[k/64] |= 1 << uint(k%64)
+		}
+	}
+	fs.first = fs.next(0)
+	return fs
+}
+
+// Len return the number of values in the set
+func (fs FieldSet) Len() int {
+	q := 0
+	for _, w := range fs.words {
+		q += bits.OnesCount64(w)
+	}
+	return q
+}
+
+// Contains return true if x is in the set
+func (fs FieldSet) Contains(x int) bool {
+	k := x - fs.min
+	if k < 0 || k >= len(fs.words)*64 {
+		return false
+	}
+	return fs.words[k/64]&(1<<uint(k%64)) != 0
+}
+
+// Ceil return the current or next greater value in the set, with the same meaning as
+// CrontabField.Ceil: if there is no such value, the least value is returned with roll set.
+func (fs FieldSet) Ceil(x int) (int, bool) {
+	k := x - fs.min
+	if k < 0 {
+		k = 0
+	}
+	k = fs.next(k)
+	if k < 0 {
+		return fs.min + fs.first, true
+	}
+	return fs.min + k, false
+}
+
+// next return the offset of the first value at or after offset k, or -1
+func (fs FieldSet) next(k int) int {
+	i := k / 64
+	if i >= len(fs.words) {
+		return -1
+	}
+	w := fs.words[i] & (^uint64(0) << uint(k%64))
+	for {
+		if w != 0 {
+			return i*64 + bits.TrailingZeros64(w)
+		}
+		i++
+		if i >= len(fs.words) {
+			return -1
+		}
+		w = fs.words[i]
+	}
+}
+
+// CompiledLine is a crontab line compiled against a config for fast matching.
+type CompiledLine struct {
+	cc    *CrontabConfig
+	line  CrontabLine
+	sets  []FieldSet
+	ranks [][]int // the fields of each of the config's units, finest first
+	plain []bool  // the field's unit is one of calendarUnit's
+}
+
+// Compile compiles the crontab line into per-field bitsets.  It return an error if the line does
+// not have a field for each field of the config, or if a field allows no values.
+func (cc *CrontabConfig) Compile(ctl CrontabLine) (*CompiledLine, error) {
+	sets, err := cc.compileSets(ctl)
+	if err != nil {
+		return nil, err
+	}
+	cl := &CompiledLine{
+		cc:    cc,
+		line:  ctl,
+		sets:  sets,
+		ranks: make([][]int, len(cc.Units)),
+		plain: make([]bool, len(cc.Fields)),
+	}
+	for k, u := range cc.Units {
+		cl.ranks[k] = cc.FieldUnits[u.String()]
+	}
+	for i, f := range cc.Fields {
+		cl.plain[i] = calendarUnit(f.Unit)
+	}
+	return cl, nil
+}
+
+// calendarUnit return true if u is one of the calendar units of this package.  Adding 0 of them
+// return the time it is given, and adding a few is cheap.  Adding 0 business days moves a time off
+// a weekend, so BusinessDayUnit is not one of them.
+func calendarUnit(u Unit) bool {
+	switch u.(type) {
+	case SecondUnit, MinuteUnit, HourUnit, DayUnit, WeekOfMonth, ISOWeekUnit, MonthUnit, YearUnit:
+		return true
+	}
+	return false
+}
+
+// compileSets compiles each field of the crontab line, sharing one allocation for the bitsets
+func (cc *CrontabConfig) compileSets(ctl CrontabLine) ([]FieldSet, error) {
+	if len(ctl) != len(cc.Fields) {
+		return nil, ErrFieldCount
+	}
+	n := 0
+	for _, f := range cc.Fields {
+		n += fieldSetWords(f.Min, f.Max)
+	}
+	words := make([]uint64, n)
+	sets := make([]FieldSet, len(ctl))
+	for i := range ctl {
+		f := cc.Fields[i]
+		n = fieldSetWords(f.Min, f.Max)
+		sets[i] = newFieldSet(f.Min, f.Max, ctl[i], words[:n:n])
+		words = words[n:]
+		if sets[i].first < 0 {
+			return nil, ErrEmptyField
+		}
+	}
+	return sets, nil
+}
+
+// ceil is CeilSet for field i of the line.  A field of a calendar unit that already allows t leaves
+// it as it is without adding 0 to it.
+func (cl *CompiledLine) ceil(i int, t time.Time) (time.Time, bool) {
+	f := cl.cc.Fields[i]
+	x0 := f.GetIndex(t)
+	x1, roll := cl.sets[i].Ceil(x0)
+	if roll {
+		return t, true
+	}
+	if x1 == x0 && cl.plain[i] {
+		return t, false
+	}
+	return f.Unit.Add(t, x1-x0), false
+}
+
+// wrap return where the search goes on from t when field i allows no value at or after its index
+// at t.  That is one unit after t, unless the field is of a calendar unit and its index steps up
+// by one there and comes round to Min just after Max, when the values up to Max are skipped
+// together.  A field that counts down, or a month that ends before day 31, takes the single step.
+func (cl *CompiledLine) wrap(i int, t time.Time) time.Time {
+	f := cl.cc.Fields[i]
+	next := f.Unit.Add(t, 1)
+	if !cl.plain[i] {
+		return next
+	}
+	x0 := f.GetIndex(t)
+	if d := f.Max - x0 + 1; d > 1 && f.GetIndex(next) == x0+1 {
+		if q := f.Unit.Add(t, d); f.GetIndex(q) == f.Min {
+			return q
+		}
+	}
+	return next
+}
+
+// Matches return true if the line fires at t: t is at the start of a period of the config's finest
+// unit and every field allows it
+func (cl *CompiledLine) Matches(t time.Time) bool {
+	if !cl.cc.Units[0].Trunc(t).Equal(t) {
+		return false
+	}
+	for i := range cl.cc.Fields {
+		// as in the search, a field matches if its ceiling leaves t where it is
+		n, roll := cl.ceil(i, t)
+		if roll || !n.Equal(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Line return the crontab line that was compiled
+func (cl *CompiledLine) Line() CrontabLine {
+	return cl.line
+}
+
+// Config return the crontab config the line was compiled against
+func (cl *CompiledLine) Config() *CrontabConfig {
+	return cl.cc
+}
+
+// Field return the compiled field at index i
+func (cl *CompiledLine) Field(i int) FieldSet {
+	return cl.sets[i]
+}
+
+func (cl *CompiledLine) String() string {
+	return cl.line.String()
+}
+
+// Next return the next time after n that matches the line
+func (cl *CompiledLine) Next(n time.Time) (time.Time, error) {
+	return cl.search(context.Background(), cl.line, n, NextOptions{})
+}
+
+// NextContext is like Next but gives up with ctx.Err() once ctx is done.
+func (cl *CompiledLine) NextContext(ctx context.Context, n time.Time) (time.Time, error) {
+	return cl.search(ctx, cl.line, n, NextOptions{})
+}
+
+// NextWithOptions is like Next but with the search limits overridden by opts.
+func (cl *CompiledLine) NextWithOptions(n time.Time, opts NextOptions) (time.Time, error) {
+	return cl.search(context.Background(), cl.line, n, opts)
+}
@@ -0,0 +1,351 @@
+package cronfab
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestFieldSet(t *testing.T) {
+	cf := CrontabField{{5, 10, 1}, {20, 40, 10}, {63, 70, 7}}
+	fs := NewFieldSet(0, 99, cf)
+	for x := 0; x <= 99; x++ {
+		v0, roll0 := cf.Ceil(x)
+		v1, roll1 := fs.Ceil(x)
+		if v0 != v1 || roll0 != roll1 {
+			t.Errorf("Ceil(%d): %d %t != %d %t", x, v1, roll1, v0, roll0)
+		}
+	}
+	expect := map[int]bool{5: true, 6: true, 7: true, 8: true, 9: true, 10: true, 20: true, 30: true, 40: true, 63: true, 70: true}
+	for x := -1; x <= 100; x++ {
+		if fs.Contains(x) != expect[x] {
+			t.Errorf("Contains(%d) = %t", x, fs.Contains(x))
+		}
+	}
+	if fs.Len() != len(expect) {
+		t.Errorf("expected %d values, got %d", len(expect), fs.Len())
+	}
+}
+
+func TestFieldSet_Offset(t *testing.T) {
+	fs := NewFieldSet(1, 12, CrontabField{{1, 12, 3}})
+	if v, roll := fs.Ceil(0); v != 1 || roll {
+		t.Errorf("expected (1, false), got (%d, %t)", v, roll)
+	}
+	if v, roll := fs.Ceil(11); v != 1 || !roll {
+		t.Errorf("expected (1, true), got (%d, %t)", v, roll)
+	}
+	if v, roll := fs.Ceil(13); v != 1 || !roll {
+		t.Errorf("expected (1, true), got (%d, %t)", v, roll)
+	}
+	// a constraint starting below the minimum keeps its step
+	fs = NewFieldSet(5, 20, CrontabField{{0, 20, 4}})
+	if v, _ := fs.Ceil(0); v != 8 {
+		t.Errorf("expected 8, got %d", v)
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	if _, err := DefaultCrontabConfig.Compile(CrontabLine{{{0, 59, 1}}}); err != ErrFieldCount {
+		t.Errorf("expected ErrFieldCount, got %v", err)
+	}
+	cl := CrontabLine{{{60, 70, 1}}, {{0, 23, 1}}, {{1, 31, 1}}, {{1, 12, 1}}, {{0, 6, 1}}}
+	if _, err := DefaultCrontabConfig.Compile(cl); err != ErrEmptyField {
+		t.Errorf("expected ErrEmptyField, got %v", err)
+	}
+}
+
+func TestCompiledLine_Next(t *testing.T) {
+	cl, err := DefaultCrontabConfig.ParseCronTab("*/15 9-17 * * mon-fri")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	compiled, err := DefaultCrontabConfig.Compile(cl)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if compiled.String() != cl.String() {
+		t.Errorf("unexpected value: %v", compiled)
+	}
+	t0 := time.Date(2021, 1, 1, 17, 45, 0, 0, time.UTC) // Friday
+	t1, err := compiled.Next(t0)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if t1.Format(time.RFC3339) != "2021-01-04T09:00:00Z" {
+		t.Errorf("unexpected value: %v", t1.Format(time.RFC3339))
+	}
+	t2, err := compiled.NextContext(context.Background(), t1)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if t2.Format(time.RFC3339) != "2021-01-04T09:15:00Z" {
+		t.Errorf("unexpected value: %v", t2.Format(time.RFC3339))
+	}
+}
+
+func TestNext_MonthBoundary(t *testing.T) {
+	// adding days to reach the 31st must not step over the 1st of the next month
+	cl, err := DefaultCrontabConfig.ParseCronTab("0 0 1,31 * *")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	next, err := DefaultCrontabConfig.Next(cl, time.Date(2021, 2, 27, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if next.Format(time.RFC3339) != "2021-03-01T00:00:00Z" {
+		t.Errorf("expected 2021-03-01T00:00:00Z, got %s", next.Format(time.RFC3339))
+	}
+}
+
+func TestNext_Unaligned(t *testing.T) {
+	// the start time is truncated to the finest unit before searching
+	cl, err := DefaultCrontabConfig.ParseCronTab("*/5 * * * *")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	next, err := DefaultCrontabConfig.Next(cl, time.Date(2021, 2, 27, 0, 1, 30, 5, time.UTC))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if next.Format(time.RFC3339Nano) != "2021-02-27T00:05:00Z" {
+		t.Errorf("expected 2021-02-27T00:05:00Z, got %s", next.Format(time.RFC3339Nano))
+	}
+}
+
+// bruteNext scans minute by minute for the next match of a DefaultCrontabConfig line
+func bruteNext(cl *CompiledLine, n time.Time) time.Time {
+	n = MinuteUnit{}.Trunc(n)
+	for {
+		n = n.Add(time.Minute)
+		ok := true
+		for i, f := range DefaultCrontabConfig.Fields {
+			if !cl.Field(i).Contains(f.GetIndex(n)) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return n
+		}
+	}
+}
+
+func TestNext_MonthEnd(t *testing.T) {
+	// a match on the 31st must not roll the month past February
+	cl, err := DefaultCrontabConfig.ParseCronTab("0 0 * 2 *")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	next, err := DefaultCrontabConfig.Next(cl, time.Date(2024, 1, 30, 23, 59, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if next.Format(time.RFC3339) != "2024-02-01T00:00:00Z" {
+		t.Errorf("expected 2024-02-01T00:00:00Z, got %s", next.Format(time.RFC3339))
+	}
+}
+
+// randField return a random field for f: every value, or up to three stepped ranges
+func randField(rnd *rand.Rand, f FieldConfig) CrontabField {
+	if rnd.Intn(3) == 0 {
+		return CrontabField{{f.Min, f.Max, 1}}
+	}
+	var cf CrontabField
+	x := f.Min + rnd.Intn(f.Max-f.Min+1)
+	for x <= f.Max && len(cf) < 3 {
+		y := x + rnd.Intn(f.Max-x+1)
+		cf = append(cf, [3]int{x, y, 1 + rnd.Intn(4)})
+		x = y + 1 + rnd.Intn(f.Max-f.Min+1)
+	}
+	return cf
+}
+
+func TestNext_BruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		var line CrontabLine
+		for _, f := range DefaultCrontabConfig.Fields {
+			line = append(line, randField(rnd, f))
+		}
+		// keep the search short enough to brute force
+		line[3] = CrontabField{{1, 12, 1}}
+		cl, err := DefaultCrontabConfig.Compile(line)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		t0 := time.Date(2020, time.Month(1+rnd.Intn(12)), 1+rnd.Intn(28), rnd.Intn(24), rnd.Intn(60), 0, 0, time.UTC)
+		got, err := cl.Next(t0)
+		if err != nil {
+			t.Fatalf("%v from %v: err: %v", line, t0, err)
+		}
+		expect := bruteNext(cl, t0)
+		if !got.Equal(expect) {
+			t.Errorf("%v from %v: got %v, expected %v", line, t0, got, expect)
+		}
+	}
+}
+
+func TestNext_Linear(t *testing.T) {
+	// the compiled search skips the values a field has run out of, which the search without
+	// compiled lines steps through one at a time; both must land on the same times
+	rnd := rand.New(rand.NewSource(2))
+	for _, cc := range []*CrontabConfig{DefaultCrontabConfig, SecondCrontabConfig, ExtendedCrontabConfig, BusinessCrontabConfig} {
+		for i := 0; i < 100; i++ {
+			var line CrontabLine
+			for _, f := range cc.Fields {
+				if rnd.Intn(4) == 0 {
+					// the first values are the ones a field runs out of last
+					x := f.Min + rnd.Intn(3)
+					line = append(line, CrontabField{{x, x, 1}})
+					continue
+				}
+				line = append(line, randField(rnd, f))
+			}
+			cl, err := cc.Compile(line)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			n := time.Date(2020+rnd.Intn(10), time.Month(1+rnd.Intn(12)), 1+rnd.Intn(28), rnd.Intn(24), rnd.Intn(60), 0, 0, time.UTC)
+			expect := n
+			for j := 0; j < 3; j++ {
+				// lines that fire rarely, or never, are left out
+				n, err = cl.NextWithOptions(n, NextOptions{MaxIt: 1 << 20, MaxSpan: 4 * 366 * 24 * time.Hour})
+				if err != nil {
+					break
+				}
+				expect = nextLinear(cc, line, expect)
+				if !n.Equal(expect) {
+					t.Errorf("%s %v: got %v, expected %v", cc.Name, line, n, expect)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestNext_Cached(t *testing.T) {
+	cc := DefaultCrontabConfig.Copy()
+	line, err := cc.ParseCronTab("0 9 * * *")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	t0 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := cc.Next(line, t0); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := cc.Next(line, t0); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+	// the cache keeps its own copy of the line
+	line[1][0] = [3]int{10, 10, 1}
+	next, err := cc.Next(line, t0)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if want := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("expected %v, got %v", want, next)
+	}
+}
+
+// nextLinear is the search without compiled lines: each field walks its
+// constraints on every call.  It is kept for the benchmarks.
+func nextLinear(cc *CrontabConfig, ctl CrontabLine, n time.Time) time.Time {
+	unitsRank := cc.Units
+	u := unitsRank[0]
+	n = u.Add(u.Trunc(n), 1)
+	roll := false
+	newn := time.Time{}
+	k := 0
+	for k < len(unitsRank) {
+		for k = 0; k < len(unitsRank); k++ {
+			u = unitsRank[k]
+			for _, i := range cc.FieldUnits[u.String()] {
+				newn, roll = cc.Fields[i].Ceil(ctl[i], n)
+				if !newn.Equal(n) || roll {
+					break
+				}
+			}
+			if roll {
+				newn = unitsRank[k].Add(newn, 1)
+				newn = unitsRank[k].Trunc(newn)
+			}
+			if !newn.Equal(n) || roll {
+				newn = cc.clamp(k, n, cc.reset(k, n, newn))
+				break
+			}
+		}
+		n = newn
+	}
+	return n
+}
+
+const benchLine = "5,10,15,20,25,30,35,40,45,50,55 9,10,11,12,13,14,15,16,17 1,8,15,22 * mon,wed,fri"
+
+func BenchmarkFieldCeil_Linear(b *testing.B) {
+	cf := CrontabField{{0, 0, 1}, {7, 7, 1}, {14, 14, 1}, {21, 21, 1}, {28, 28, 1}, {35, 35, 1}, {42, 42, 1}, {49, 49, 1}, {56, 56, 1}}
+	for i := 0; i < b.N; i++ {
+		cf.Ceil(i % 60)
+	}
+}
+
+func BenchmarkFieldCeil_Compiled(b *testing.B) {
+	cf := CrontabField{{0, 0, 1}, {7, 7, 1}, {14, 14, 1}, {21, 21, 1}, {28, 28, 1}, {35, 35, 1}, {42, 42, 1}, {49, 49, 1}, {56, 56, 1}}
+	fs := NewFieldSet(0, 59, cf)
+	for i := 0; i < b.N; i++ {
+		fs.Ceil(i % 60)
+	}
+}
+
+func BenchmarkNext_Linear(b *testing.B) {
+	cl, err := DefaultCrontabConfig.ParseCronTab(benchLine)
+	if err != nil {
+		b.Fatalf("err: %v", err)
+	}
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		t0 = nextLinear(DefaultCrontabConfig, cl, t0)
+	}
+}
+
+func BenchmarkNext(b *testing.B) {
+	cl, err := DefaultCrontabConfig.ParseCronTab(benchLine)
+	if err != nil {
+		b.Fatalf("err: %v", err)
+	}
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		t0, err = DefaultCrontabConfig.Next(cl, t0)
+		if err != nil {
+			b.Fatalf("err: %v", err)
+		}
+	}
+}
+
+func BenchmarkNext_Compiled(b *testing.B) {
+	cl, err := DefaultCrontabConfig.ParseCronTab(benchLine)
+	if err != nil {
+		b.Fatalf("err: %v", err)
+	}
+	compiled, err := DefaultCrontabConfig.Compile(cl)
+	if err != nil {
+		b.Fatalf("err: %v", err)
+	}
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		t0, err = compiled.Next(t0)
+		if err != nil {
+			b.Fatalf("err: %v", err)
+		}
+	}
+}
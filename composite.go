@@ -0,0 +1,259 @@
+package cronfab
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoSchedules = errors.New("composite has no schedules")
+)
+
+// CompositeOp is how a Composite combines its schedules
+type CompositeOp int
+
+const (
+	// OpUnion fires when any of the schedules fires
+	OpUnion CompositeOp = iota
+	// OpIntersect fires when all of the schedules fire at once
+	OpIntersect
+	// OpExcept fires when the first schedule fires and none of the others do
+	OpExcept
+)
+
+func (op CompositeOp) String() string {
+	switch op {
+	case OpUnion:
+		return "union"
+	case OpIntersect:
+		return "intersect"
+	case OpExcept:
+		return "except"
+	}
+	return fmt.Sprintf("CompositeOp(%d)", int(op))
+}
+
+// Composite is a Schedule made of other schedules, like "every weekday at 9:00 except the 25th of
+// December"
+type Composite struct {
+	Op        CompositeOp
+	Schedules []Schedule
+	// MaxIt is the number of candidate times Next may reject before giving up.  DefaultMaxIt if
+	// zero.  Each schedule's own search has its own budget.
+	MaxIt int
+	// MaxSpan is how far past the start time Next may search.  Unlimited if zero.
+	MaxSpan time.Duration
+}
+
+// Union return a schedule that fires whenever any of the schedules fires
+func Union(schedules ...Schedule) *Composite {
+	return &Composite{Op: OpUnion, Schedules: schedules}
+}
+
+// Intersect return a schedule that fires when all of the schedules fire at the same time
+func Intersect(schedules ...Schedule) *Composite {
+	return &Composite{Op: OpIntersect, Schedules: schedules}
+}
+
+// Except return a schedule that fires when base fires and none of the exclusions do
+func Except(base Schedule, exclusions ...Schedule) *Composite {
+	return &Composite{Op: OpExcept, Schedules: append([]Schedule{base}, exclusions...)}
+}
+
+// Union compiles the lines and return their union, with the config's search limits
+func (cc *CrontabConfig) Union(lines ...CrontabLine) (*Composite, error) {
+	return cc.composite(OpUnion, lines)
+}
+
+// Intersect compiles the lines and return their intersection, with the config's search limits
+func (cc *CrontabConfig) Intersect(lines ...CrontabLine) (*Composite, error) {
+	return cc.composite(OpIntersect, lines)
+}
+
+// Except compiles the lines and return base without the exclusions, with the config's search
+// limits
+func (cc *CrontabConfig) Except(base CrontabLine, exclusions ...CrontabLine) (*Composite, error) {
+	return cc.composite(OpExcept, append([]CrontabLine{base}, exclusions...))
+}
+
+// composite compiles lines into a composite schedule
+func (cc *CrontabConfig) composite(op CompositeOp, lines []CrontabLine) (*Composite, error) {
+	c := &Composite{Op: op, MaxIt: cc.MaxIt, MaxSpan: cc.MaxSpan}
+	for _, ctl := range lines {
+		cl, err := cc.Compile(ctl)
+		if err != nil {
+			return nil, err
+		}
+		c.Schedules = append(c.Schedules, cl)
+	}
+	return c, nil
+}
+
+// Next return the next time after t that the composite fires.  A union fails only if all of its
+// schedules fail; it is otherwise the earliest of the times found.
+func (c *Composite) Next(t time.Time) (time.Time, error) {
+	if len(c.Schedules) == 0 {
+		return time.Time{}, ErrNoSchedules
+	}
+	switch c.Op {
+	case OpUnion:
+		return c.union(t)
+	case OpIntersect:
+		return c.intersect(t)
+	case OpExcept:
+		return c.except(t)
+	}
+	return time.Time{}, fmt.Errorf("unknown composite op %v", c.Op)
+}
+
+// union return the earliest next time of the schedules
+func (c *Composite) union(t time.Time) (time.Time, error) {
+	var q time.Time
+	var qerr error
+	for _, s := range c.Schedules {
+		n, err := s.Next(t)
+		if err != nil {
+			qerr = err
+			continue
+		}
+		if q.IsZero() || n.Before(q) {
+			q = n
+		}
+	}
+	if q.IsZero() {
+		return q, qerr
+	}
+	return q, nil
+}
+
+// intersect moves a candidate forward to the latest of the schedules' first times at or after it
+// until they all agree
+func (c *Composite) intersect(t time.Time) (time.Time, error) {
+	cand, err := c.Schedules[0].Next(t)
+	if err != nil {
+		return cand, err
+	}
+	for j := 0; ; j++ {
+		if err := c.limit(t, cand, j); err != nil {
+			return cand, err
+		}
+		agree := true
+		for _, s := range c.Schedules {
+			if matches(s, cand) {
+				continue
+			}
+			n, err := s.Next(cand)
+			if err != nil {
+				return n, err
+			}
+			cand = n
+			agree = false
+			break
+		}
+		if agree {
+			return cand, nil
+		}
+	}
+}
+
+// except steps through the times of the first schedule until one that no other schedule fires at
+func (c *Composite) except(t time.Time) (time.Time, error) {
+	cand := t
+	for j := 0; ; j++ {
+		n, err := c.Schedules[0].Next(cand)
+		if err != nil {
+			return n, err
+		}
+		cand = n
+		if err := c.limit(t, cand, j); err != nil {
+			return cand, err
+		}
+		excluded := false
+		for _, s := range c.Schedules[1:] {
+			if matches(s, cand) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			return cand, nil
+		}
+	}
+}
+
+// limit return an error once the search has tried too many candidates or gone too far
+func (c *Composite) limit(start, reached time.Time, j int) error {
+	maxIt := c.MaxIt
+	if maxIt <= 0 {
+		maxIt = DefaultMaxIt
+	}
+	if j > maxIt {
+		return &ErrorSearchLimit{Err: ErrMaxit, Start: start, Reached: reached, Iterations: j}
+	}
+	if c.MaxSpan > 0 && reached.Sub(start) > c.MaxSpan {
+		return &ErrorSearchLimit{Err: ErrMaxSpan, Start: start, Reached: reached, Iterations: j}
+	}
+	return nil
+}
+
+// matcher is a schedule that can tell whether it fires at a time without searching
+type matcher interface {
+	Matches(t time.Time) bool
+}
+
+// matches return true if s fires at t
+func matches(s Schedule, t time.Time) bool {
+	if m, ok := s.(matcher); ok {
+		return m.Matches(t)
+	}
+	n, err := s.Next(t.Add(-time.Nanosecond))
+	return err == nil && n.Equal(t)
+}
+
+// Matches return true if the composite fires at t
+func (c *Composite) Matches(t time.Time) bool {
+	if len(c.Schedules) == 0 {
+		return false
+	}
+	switch c.Op {
+	case OpUnion:
+		for _, s := range c.Schedules {
+			if matches(s, t) {
+				return true
+			}
+		}
+		return false
+	case OpIntersect:
+		for _, s := range c.Schedules {
+			if !matches(s, t) {
+				return false
+			}
+		}
+		return true
+	case OpExcept:
+		if !matches(c.Schedules[0], t) {
+			return false
+		}
+		for _, s := range c.Schedules[1:] {
+			if matches(s, t) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (c *Composite) String() string {
+	parts := make([]string, len(c.Schedules))
+	for i, s := range c.Schedules {
+		if str, ok := s.(fmt.Stringer); ok {
+			parts[i] = str.String()
+		} else {
+			parts[i] = fmt.Sprintf("%T", s)
+		}
+	}
+	return c.Op.String() + "(" + strings.Join(parts, "; ") + ")"
+}
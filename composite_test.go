@@ -0,0 +1,168 @@
+package cronfab
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustLine(t *testing.T, cc *CrontabConfig, s string) CrontabLine {
+	t.Helper()
+	ctl, err := cc.ParseCronTab(s)
+	if err != nil {
+		t.Fatalf("%q: %v", s, err)
+	}
+	return ctl
+}
+
+func collect(t *testing.T, s Schedule, start time.Time, n int) []time.Time {
+	t.Helper()
+	var q []time.Time
+	for i := 0; i < n; i++ {
+		var err error
+		start, err = s.Next(start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q = append(q, start)
+	}
+	return q
+}
+
+func TestComposite_Except(t *testing.T) {
+	cc := DefaultCrontabConfig
+	c, err := cc.Except(mustLine(t, cc, "0 9 * * mon-fri"), mustLine(t, cc, "0 9 25 dec *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collect(t, c, time.Date(2024, 12, 23, 12, 0, 0, 0, time.UTC), 3)
+	want := []time.Time{
+		time.Date(2024, 12, 24, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 12, 26, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 12, 27, 9, 0, 0, 0, time.UTC),
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("%d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+	if !c.Matches(want[0]) || c.Matches(time.Date(2024, 12, 25, 9, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected Matches")
+	}
+}
+
+func TestComposite_Union(t *testing.T) {
+	cc := DefaultCrontabConfig
+	c, err := cc.Union(mustLine(t, cc, "0 9 * * *"), mustLine(t, cc, "30 17 * * *"), mustLine(t, cc, "0 9 1 * *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collect(t, c, time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC), 3)
+	want := []time.Time{
+		time.Date(2024, 2, 29, 17, 30, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 17, 30, 0, 0, time.UTC),
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("%d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestComposite_Intersect(t *testing.T) {
+	cc := DefaultCrontabConfig
+	every, err := NewIntervalSchedule(90*time.Minute, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hours, err := cc.Compile(mustLine(t, cc, "* 9-17 * * mon-fri"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Intersect(hours, every)
+	got := collect(t, c, time.Date(2024, 1, 5, 16, 0, 0, 0, time.UTC), 4)
+	want := []time.Time{
+		time.Date(2024, 1, 5, 16, 30, 0, 0, time.UTC),
+		time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 8, 10, 30, 0, 0, time.UTC),
+		time.Date(2024, 1, 8, 12, 0, 0, 0, time.UTC),
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("%d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestComposite_Empty(t *testing.T) {
+	cc := MustCrontabConfig(DefaultCrontabConfig.Fields)
+	cc.MaxIt = 100
+	c, err := cc.Intersect(mustLine(t, cc, "0 9 * * *"), mustLine(t, cc, "0 10 * * *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err = c.Next(start)
+	var esl *ErrorSearchLimit
+	if !errors.As(err, &esl) || !errors.Is(err, ErrMaxit) {
+		t.Fatalf("expected a search limit error, got %v", err)
+	}
+	if esl.Iterations != 101 {
+		t.Errorf("expected 101 iterations, got %d", esl.Iterations)
+	}
+
+	c, err = cc.Except(mustLine(t, cc, "0 9 * * *"), mustLine(t, cc, "0 * * * *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.MaxIt = 0
+	c.MaxSpan = 30 * 24 * time.Hour
+	if _, err := c.Next(start); !errors.Is(err, ErrMaxSpan) {
+		t.Errorf("expected %v, got %v", ErrMaxSpan, err)
+	}
+
+	if _, err := Union().Next(start); !errors.Is(err, ErrNoSchedules) {
+		t.Errorf("expected %v, got %v", ErrNoSchedules, err)
+	}
+}
+
+func TestComposite_Nested(t *testing.T) {
+	cc := DefaultCrontabConfig
+	weekdays, err := cc.ParseSchedule("0 9 * * mon-fri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	holidays, err := cc.Union(mustLine(t, cc, "0 9 25 dec *"), mustLine(t, cc, "0 9 1 jan *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Except(weekdays, holidays)
+	got := collect(t, c, time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC), 1)
+	if want := time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC); !got[0].Equal(want) {
+		t.Errorf("expected %v, got %v", want, got[0])
+	}
+	if s := c.String(); s != "except("+weekdays.(*CompiledLine).String()+"; union("+holidays.Schedules[0].(*CompiledLine).String()+"; "+holidays.Schedules[1].(*CompiledLine).String()+"))" {
+		t.Errorf("unexpected %q", s)
+	}
+}
+
+func TestCompiledLine_Matches(t *testing.T) {
+	cl, err := DefaultCrontabConfig.Compile(mustLine(t, DefaultCrontabConfig, "30 9 * * mon"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		t    time.Time
+		want bool
+	}{
+		{time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC), true},
+		{time.Date(2024, 1, 1, 9, 30, 1, 0, time.UTC), false},
+		{time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC), false},
+		{time.Date(2024, 1, 1, 9, 31, 0, 0, time.UTC), false},
+	} {
+		if got := cl.Matches(tc.t); got != tc.want {
+			t.Errorf("%v: expected %v, got %v", tc.t, tc.want, got)
+		}
+	}
+}
@@ -1,24 +1,51 @@
 package cronfab
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 )
 
 var (
-	ErrMaxit = errors.New("maximum number of iterations met")
+	ErrMaxit   = errors.New("maximum number of iterations met")
+	ErrMaxSpan = errors.New("maximum search span met")
 )
 
+// DefaultMaxIt is the search iteration budget used when neither the config nor the call sets one
+const DefaultMaxIt = 20000
+
 // CrontabConfig models the possible time specifications for a crontab entry
 type CrontabConfig struct {
+	// Name identifies the config in persisted schedules.  The built-in configs are "default" and
+	// "second".
+	Name       string
 	Fields     []FieldConfig
 	FieldUnits map[string][]int
 	Units      []Unit
 	Aliases    map[string]string
+	// MaxIt is the maximum number of iterations Next may take.  DefaultMaxIt if zero.
+	MaxIt int
+	// MaxSpan is how far past the start time Next may search.  Unlimited if zero.
+	MaxSpan time.Duration
+	// Strict makes ParseCronTab reject lines that Satisfiable rejects.
+	Strict bool
+	// Observer, if set, is told the cost of every search made by Next and its variants.
+	Observer SearchObserver
+	// IntervalAnchor aligns the "@every" schedules of ParseSchedule.  The Unix epoch if zero.
+	IntervalAnchor time.Time
+
+	// lines are the lines Next compiled last
+	lines *lineCache
+}
+
+// NextOptions overrides the search limits of a CrontabConfig for a single call.
+// Zero values fall back to the config's limits.
+type NextOptions struct {
+	MaxIt   int
+	MaxSpan time.Duration
 }
 
 // NewCrontabConfig returns a new crontab config for the supplied field configs.
@@ -44,6 +71,8 @@ func NewCrontabConfig(fields []FieldConfig) (*CrontabConfig, error) {
 	q := &CrontabConfig{
 		Fields:     fields,
 		FieldUnits: map[string][]int{},
+		MaxIt:      DefaultMaxIt,
+		lines:      &lineCache{},
 	}
 	unms := map[string]struct{}{}
 	for i := 0; i < len(q.Fields); i++ {
@@ -71,54 +100,128 @@ func MustCrontabConfig(fields []FieldConfig) *CrontabConfig {
 	return cc
 }
 
-var (
-	MaxIt = 20000
-)
+// Copy return a copy of the config with its own Aliases map, so that aliases registered on the
+// copy leave the config alone.  The field configs are shared.
+func (cc *CrontabConfig) Copy() *CrontabConfig {
+	q := *cc
+	q.lines = &lineCache{}
+	if cc.Aliases != nil {
+		q.Aliases = make(map[string]string, len(cc.Aliases))
+		for name, expr := range cc.Aliases {
+			q.Aliases[name] = expr
+		}
+	}
+	return &q
+}
 
-func init() {
-	s := os.Getenv("CRONFAB_MAXIT")
-	if s == "" {
-		return
+// Next return the next time after n as specified in the CrontabLine.  The config keeps the lines it
+// compiled last, so searching the same line again does not compile it again.
+func (cc *CrontabConfig) Next(ctl CrontabLine, n time.Time) (time.Time, error) {
+	return cc.NextWithOptions(ctl, n, NextOptions{})
+}
+
+// NextWithOptions is like Next but with the search limits overridden by opts.
+// If a limit is met the error is an *ErrorSearchLimit reporting how far the search got.
+func (cc *CrontabConfig) NextWithOptions(ctl CrontabLine, n time.Time, opts NextOptions) (time.Time, error) {
+	return cc.NextContextWithOptions(context.Background(), ctl, n, opts)
+}
+
+// NextContext is like Next but gives up with ctx.Err() once ctx is done.
+func (cc *CrontabConfig) NextContext(ctx context.Context, ctl CrontabLine, n time.Time) (time.Time, error) {
+	return cc.NextContextWithOptions(ctx, ctl, n, NextOptions{})
+}
+
+// NextContextWithOptions is like NextWithOptions but gives up with ctx.Err() once ctx is done.
+// The context is checked on every search iteration, so costly GetIndex functions bound the latency.
+func (cc *CrontabConfig) NextContextWithOptions(ctx context.Context, ctl CrontabLine, n time.Time, opts NextOptions) (time.Time, error) {
+	cl, err := cc.compiled(ctl)
+	if err != nil {
+		return n, err
+	}
+	return cl.search(ctx, ctl, n, opts)
+}
+
+// compiled return the line compiled against the config, from the config's cache if it is there
+func (cc *CrontabConfig) compiled(ctl CrontabLine) (*CompiledLine, error) {
+	if cc.lines == nil {
+		return cc.Compile(ctl)
+	}
+	if cl := cc.lines.get(cc, ctl); cl != nil {
+		return cl, nil
 	}
-	x, err := strconv.Atoi(s)
+	cl, err := cc.Compile(copyLine(ctl))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "cronfab: invalid CRONFAB_MAXIT value %q: %v\n", s, err)
-		return
+		return nil, err
 	}
-	if x > 0 {
-		MaxIt = x
+	cc.lines.put(cl)
+	return cl, nil
+}
+
+// search return the next time after n that matches the line, reporting the search of ctl to the
+// config's observer if it has one
+func (cl *CompiledLine) search(ctx context.Context, ctl CrontabLine, n time.Time, opts NextOptions) (time.Time, error) {
+	if cl.cc.Observer == nil {
+		return cl.find(ctx, n, opts, nil)
 	}
+	var st SearchStats
+	t, err := cl.find(ctx, n, opts, &st)
+	cl.cc.Observer.ObserveSearch(ctl, st, err)
+	return t, err
 }
 
-// Next return the next time after n as specified in the CrontabLine
-func (cc *CrontabConfig) Next(ctl CrontabLine, n time.Time) (time.Time, error) {
+// find return the next time after n that matches the line.  If st is not nil it is filled in with
+// the cost of the search.
+func (cl *CompiledLine) find(ctx context.Context, n time.Time, opts NextOptions, st *SearchStats) (time.Time, error) {
+	cc := cl.cc
+	j := 0
+	if st != nil {
+		began := time.Now()
+		st.Rolls = make([]int, len(cc.Units))
+		defer func() {
+			st.Iterations = j
+			st.Elapsed = time.Since(began)
+		}()
+	}
+	opts = cc.limits(opts)
+	done := ctx.Done()
+	start := n
 	unitsRank := cc.Units
 	u := unitsRank[0]
-	n = u.Add(n, 1)
+	n = u.Add(u.Trunc(n), 1)
 	roll := false
 	newn := time.Time{}
 	k := 0
-	j := 0
 	for k < len(unitsRank) {
+		select {
+		case <-done:
+			return n, ctx.Err()
+		default:
+		}
 		for k = 0; k < len(unitsRank); k++ {
-			u = unitsRank[k]
-			fieldsForUnit := cc.FieldUnits[u.String()]
-			for _, i := range fieldsForUnit {
-				newn, roll = cc.Fields[i].Ceil(ctl[i], n)
+			r := -1
+			for _, i := range cl.ranks[k] {
+				newn, roll = cl.ceil(i, n)
 				if !newn.Equal(n) || roll {
+					r = i
 					break
 				}
 			}
 			if roll {
-				newn = unitsRank[k].Add(newn, 1)
-				newn = unitsRank[k].Trunc(newn)
+				newn = unitsRank[k].Trunc(cl.wrap(r, n))
 			}
 			if !newn.Equal(n) || roll {
+				newn = cc.clamp(k, n, cc.reset(k, n, newn))
+				if st != nil {
+					st.Rolls[k]++
+				}
 				break
 			}
 		}
-		if j > MaxIt {
-			return n, ErrMaxit
+		if j > opts.MaxIt {
+			return n, &ErrorSearchLimit{Err: ErrMaxit, Start: start, Reached: n, Iterations: j}
+		}
+		if opts.MaxSpan > 0 && n.Sub(start) > opts.MaxSpan {
+			return n, &ErrorSearchLimit{Err: ErrMaxSpan, Start: start, Reached: n, Iterations: j}
 		}
 		j++
 		n = newn
@@ -126,6 +229,45 @@ func (cc *CrontabConfig) Next(ctl CrontabLine, n time.Time) (time.Time, error) {
 	return n, nil
 }
 
+// reset return newn with the components finer than rank k zeroed, as long as that stays after n.
+// A field that moves forward lands in a later period of its unit, and the first match in that
+// period can be at its very start.
+func (cc *CrontabConfig) reset(k int, n, newn time.Time) time.Time {
+	b := cc.Units[k].Trunc(newn)
+	if b.After(n) {
+		return b
+	}
+	return newn
+}
+
+// clamp return the start of the first period of a unit coarser than rank k that begins after n
+// and before newn, or newn if there is none.  Adding units of rank k can step over such a
+// boundary, e.g. from February 28 to March 3 when looking for the 31st, and skip the times
+// just after it.
+func (cc *CrontabConfig) clamp(k int, n, newn time.Time) time.Time {
+	for _, u := range cc.Units[k+1:] {
+		b := u.Trunc(newn)
+		if b.After(n) && b.Before(newn) {
+			newn = b
+		}
+	}
+	return newn
+}
+
+// limits return opts with the zero limits filled in from the config
+func (cc *CrontabConfig) limits(opts NextOptions) NextOptions {
+	if opts.MaxIt <= 0 {
+		opts.MaxIt = cc.MaxIt
+	}
+	if opts.MaxIt <= 0 {
+		opts.MaxIt = DefaultMaxIt
+	}
+	if opts.MaxSpan <= 0 {
+		opts.MaxSpan = cc.MaxSpan
+	}
+	return opts
+}
+
 // NameToNumber convert a constraint mnemonic to an index
 func (cc *CrontabConfig) NameToNumber(i int, s string) int {
 	return lookupNameIndex(cc.Fields[i].RangeNames, s)
@@ -1,6 +1,7 @@
 package cronfab
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -300,6 +301,9 @@ func TestMonthUnit(t *testing.T) {
 	if t1.Month() != 3 {
 		t.Errorf("expected March, got %v", t1.Month())
 	}
+	if got := u.Add(time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), 1); !got.Equal(time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the last day of February, got %v", got)
+	}
 	// Trunc
 	t2 := u.Trunc(time.Date(2020, 3, 15, 12, 30, 0, 0, time.UTC))
 	_ = t2 // just exercise the code path
@@ -604,17 +608,25 @@ func TestNext_EndOfYear(t *testing.T) {
 
 func TestNext_MultipleConstraintsPerField(t *testing.T) {
 	// "0,30 9,17 * * *" → on the hour and half hour at 9am and 5pm
-	// Note: Next always advances by 1 smallest unit first, and the ceiling
-	// logic may combine field ceilings, so from 08:00 the first hit is 09:30
-	// (minute ceils 01→30, then hour ceils 8→9).
+	// Note: Next always advances by 1 smallest unit first.  When a coarser
+	// field moves forward the finer fields are reset, so from 08:00 the first
+	// hit is 09:00 (minute ceils 01→30, hour ceils 8→9 and resets to 09:00).
 	cl, err := DefaultCrontabConfig.ParseCronTab("0,30 9,17 * * *")
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
+	next, err := DefaultCrontabConfig.Next(cl, time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if next.Format(time.RFC3339) != "2020-01-01T09:00:00Z" {
+		t.Errorf("expected 2020-01-01T09:00:00Z, got %s", next.Format(time.RFC3339))
+	}
+
 	start := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
 	// From 09:00, advance 1 min to 09:01, ceil minute to 30 → 09:30
-	next, err := DefaultCrontabConfig.Next(cl, start)
+	next, err = DefaultCrontabConfig.Next(cl, start)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -713,18 +725,90 @@ func TestCrontabField_ValidateNonOverlapping(t *testing.T) {
 
 func TestNext_ErrMaxit(t *testing.T) {
 	// Feb 31 doesn't exist, so this should exhaust MaxIt
-	oldMaxIt := MaxIt
-	MaxIt = 100 // reduce so this test doesn't take forever
-	defer func() { MaxIt = oldMaxIt }()
+	cl, err := DefaultCrontabConfig.ParseCronTab("0 0 31 feb *")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	// reduce the budget so this test doesn't take forever
+	_, err = DefaultCrontabConfig.NextWithOptions(cl, start, NextOptions{MaxIt: 100})
+	if !errors.Is(err, ErrMaxit) {
+		t.Fatalf("expected ErrMaxit, got %v", err)
+	}
+	var limit *ErrorSearchLimit
+	if !errors.As(err, &limit) {
+		t.Fatalf("expected *ErrorSearchLimit, got %T", err)
+	}
+	if limit.Iterations != 101 || !limit.Start.Equal(start) || !limit.Reached.After(start) {
+		t.Errorf("unexpected progress: %+v", limit)
+	}
+}
+
+func TestNext_ErrMaxitConfig(t *testing.T) {
+	cc := MustCrontabConfig(DefaultCrontabConfig.Fields)
+	if cc.MaxIt != DefaultMaxIt {
+		t.Fatalf("expected MaxIt %d, got %d", DefaultMaxIt, cc.MaxIt)
+	}
+	cc.MaxIt = 10
+	cl, err := cc.ParseCronTab("0 0 31 feb *")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, err = cc.Next(cl, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	var limit *ErrorSearchLimit
+	if !errors.As(err, &limit) || limit.Err != ErrMaxit || limit.Iterations != 11 {
+		t.Errorf("expected ErrMaxit after 11 iterations, got %v", err)
+	}
+}
 
+func TestNext_ErrMaxSpan(t *testing.T) {
 	cl, err := DefaultCrontabConfig.ParseCronTab("0 0 31 feb *")
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
-	_, err = DefaultCrontabConfig.Next(cl, start)
-	if err != ErrMaxit {
-		t.Errorf("expected ErrMaxit, got %v", err)
+	span := 10 * 365 * 24 * time.Hour
+	_, err = DefaultCrontabConfig.NextWithOptions(cl, start, NextOptions{MaxSpan: span})
+	if !errors.Is(err, ErrMaxSpan) {
+		t.Fatalf("expected ErrMaxSpan, got %v", err)
+	}
+	var limit *ErrorSearchLimit
+	if !errors.As(err, &limit) {
+		t.Fatalf("expected *ErrorSearchLimit, got %T", err)
+	}
+	if limit.Reached.Sub(start) <= span {
+		t.Errorf("search stopped early at %v", limit.Reached)
+	}
+	if limit.Reached.Year() > 2031 {
+		t.Errorf("search ran well past the span: %v", limit.Reached)
+	}
+}
+
+func TestNext_SpanNotMet(t *testing.T) {
+	cl, err := DefaultCrontabConfig.ParseCronTab("0 0 29 feb *")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	next, err := DefaultCrontabConfig.NextWithOptions(cl, start, NextOptions{MaxSpan: 4 * 366 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if next.Year() != 2024 {
+		t.Errorf("expected 2024-02-29, got %v", next)
+	}
+}
+
+func TestErrorSearchLimit_Error(t *testing.T) {
+	e := &ErrorSearchLimit{
+		Err:        ErrMaxit,
+		Start:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Reached:    time.Date(2031, 3, 1, 0, 0, 0, 0, time.UTC),
+		Iterations: 20001,
+	}
+	s := e.Error()
+	if s != "maximum number of iterations met: searched from 2020-01-01T00:00:00Z to 2031-03-01T00:00:00Z in 20001 iterations" {
+		t.Errorf("unexpected: %q", s)
 	}
 }
 
@@ -1,6 +1,7 @@
 package cronfab
 
 import (
+	"strings"
 	"time"
 )
 
@@ -96,27 +97,74 @@ func (DayUnit) Trunc(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 }
 
-// WeekOfMonth units in week in month ordinal
-type WeekOfMonth struct{}
+// WeekOfMonth units in week in month ordinal.  The zero value has weeks that are the rows of a
+// calendar starting on Sunday.
+type WeekOfMonth struct {
+	// FirstDay is the weekday the rows of the calendar start on
+	FirstDay time.Weekday
+	// Ordinal makes the weeks 7 day blocks from the first of the month instead of rows, so that
+	// days 1 to 7 are the first week and days 29 to the end of the month the fifth.  FirstDay is
+	// not used.
+	Ordinal bool
+}
 
-func (WeekOfMonth) String() string {
+func (u WeekOfMonth) String() string {
+	switch {
+	case u.Ordinal:
+		return "ordinal week"
+	case u.FirstDay != time.Sunday:
+		return "week from " + strings.ToLower(u.FirstDay.String())
+	}
 	return "week"
 }
 
-func (WeekOfMonth) Add(t time.Time, n int) time.Time {
+// Add moves rows by 7 days.  Ordinal weeks move by blocks, keeping the day within the block, or
+// using the last day of a shorter block.
+func (u WeekOfMonth) Add(t time.Time, n int) time.Time {
+	if u.Ordinal {
+		return addOrdinalWeeks(t, n)
+	}
 	return t.AddDate(0, 0, n*7)
 }
 
 func (WeekOfMonth) Less(u Unit) bool {
 	switch u.(type) {
-	case SecondUnit, MinuteUnit, HourUnit, DayUnit, WeekOfMonth:
+	case SecondUnit, MinuteUnit, HourUnit, DayUnit, BusinessDayUnit, WeekOfMonth:
 		return false
 	}
 	return true
 }
 
-func (WeekOfMonth) Trunc(t time.Time) time.Time {
-	return time.Date(t.Year(), t.Month(), t.Day()-int(t.Weekday()), 0, 0, 0, 0, t.Location())
+// Trunc return the start of the row of t, which can be in the month before, or the start of the
+// ordinal week of t
+func (u WeekOfMonth) Trunc(t time.Time) time.Time {
+	if u.Ordinal {
+		return time.Date(t.Year(), t.Month(), t.Day()-(t.Day()-1)%7, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSince(t.Weekday(), u.FirstDay), 0, 0, 0, 0, t.Location())
+}
+
+// ISOWeekUnit units in ISO 8601 weeks, which start on Monday
+type ISOWeekUnit struct{}
+
+func (ISOWeekUnit) String() string {
+	return "iso week"
+}
+
+func (ISOWeekUnit) Add(t time.Time, n int) time.Time {
+	return t.AddDate(0, 0, n*7)
+}
+
+func (ISOWeekUnit) Less(u Unit) bool {
+	switch u.(type) {
+	case SecondUnit, MinuteUnit, HourUnit, DayUnit, BusinessDayUnit, WeekOfMonth, ISOWeekUnit:
+		return false
+	}
+	return true
+}
+
+func (ISOWeekUnit) Trunc(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
 }
 
 // MonthUnit units in months
@@ -126,13 +174,19 @@ func (MonthUnit) String() string {
 	return "month"
 }
 
+// Add keeps the day of the month, or uses the last day of the month when it has no such day, so
+// that adding to January 31 does not step over February.
 func (MonthUnit) Add(t time.Time, n int) time.Time {
-	return t.AddDate(0, n, 0)
+	q := t.AddDate(0, n, 0)
+	if q.Day() != t.Day() {
+		q = q.AddDate(0, 0, -q.Day())
+	}
+	return q
 }
 
 func (MonthUnit) Less(u Unit) bool {
 	switch u.(type) {
-	case SecondUnit, MinuteUnit, HourUnit, DayUnit, WeekOfMonth, MonthUnit:
+	case SecondUnit, MinuteUnit, HourUnit, DayUnit, BusinessDayUnit, WeekOfMonth, ISOWeekUnit, MonthUnit:
 		return false
 	}
 	return true
@@ -155,14 +209,14 @@ func (YearUnit) Add(t time.Time, n int) time.Time {
 
 func (YearUnit) Less(u Unit) bool {
 	switch u.(type) {
-	case SecondUnit, MinuteUnit, HourUnit, DayUnit, WeekOfMonth, MonthUnit, YearUnit:
+	case SecondUnit, MinuteUnit, HourUnit, DayUnit, BusinessDayUnit, WeekOfMonth, ISOWeekUnit, MonthUnit, YearUnit:
 		return false
 	}
 	return true
 }
 
 func (YearUnit) Trunc(t time.Time) time.Time {
-	return time.Date(t.Year(), 0, 0, 0, 0, 0, 0, t.Location())
+	return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
 }
 
 var DefaultCrontabConfig = MustCrontabConfig([]FieldConfig{
@@ -216,6 +270,8 @@ var DefaultCrontabConfig = MustCrontabConfig([]FieldConfig{
 })
 
 func init() {
+	DefaultCrontabConfig.Name = "default"
+	SecondCrontabConfig.Name = "second"
 	DefaultCrontabConfig.Aliases = map[string]string{
 		"@yearly":   "0 0 1 1 *",
 		"@annually": "0 0 1 1 *",
@@ -1,6 +1,8 @@
 package cronfab
 
 import (
+	"context"
+	"math"
 	"reflect"
 	"testing"
 	"time"
@@ -182,7 +184,7 @@ func TestNext(t *testing.T) {
 		{
 			in:     "* * * * thur",
 			start:  "0001-01-01T00:01:00Z",
-			expect: "0001-01-04T00:02:00Z",
+			expect: "0001-01-04T00:00:00Z",
 		},
 		{
 			in:     "* * * * sun",
@@ -379,8 +381,8 @@ func TestNextes(t *testing.T) {
 			start: "0001-01-01T00:01:00Z",
 			outs: map[int]string{
 				0: "0001-01-01T00:01:00Z",
-				1: "0001-01-04T00:02:00Z",
-				2: "0001-01-04T00:03:00Z",
+				1: "0001-01-04T00:00:00Z",
+				2: "0001-01-04T00:01:00Z",
 			},
 		},
 		{
@@ -471,3 +473,45 @@ func TestNextes(t *testing.T) {
 	}
 
 }
+
+func TestNextContext(t *testing.T) {
+	cl, err := DefaultCrontabConfig.ParseCronTab("*/5 * * * *")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	start := time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC)
+	next, err := DefaultCrontabConfig.NextContext(context.Background(), cl, start)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if next.Minute() != 5 {
+		t.Errorf("unexpected value: %v", next)
+	}
+}
+
+func TestNextContext_Canceled(t *testing.T) {
+	cl, err := DefaultCrontabConfig.ParseCronTab("*/5 * * * *")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = DefaultCrontabConfig.NextContext(ctx, cl, time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC))
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestNextContext_Deadline(t *testing.T) {
+	// Feb 31 never matches; with an enormous budget only the deadline stops the search
+	cl, err := DefaultCrontabConfig.ParseCronTab("0 0 31 feb *")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = DefaultCrontabConfig.NextContextWithOptions(ctx, cl, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), NextOptions{MaxIt: math.MaxInt32})
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
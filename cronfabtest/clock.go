@@ -0,0 +1,157 @@
+package cronfabtest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aalpar/cronfab"
+)
+
+// FakeClock is a cronfab.Clock that only moves when Advance or Set is called, so that tests can
+// simulate long stretches of schedule in milliseconds.  Timers fire in deadline order, with Now
+// set to each timer's deadline as it fires.  Functions passed to AfterFunc run synchronously on
+// the goroutine that advances the clock.
+type FakeClock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+var _ cronfab.Clock = (*FakeClock)(nil)
+
+// NewFakeClock return a fake clock reading now
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.changed = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) cronfab.Timer {
+	return c.newTimer(d, nil)
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) cronfab.Timer {
+	return c.newTimer(d, f)
+}
+
+func (c *FakeClock) newTimer(d time.Duration, f func()) *fakeTimer {
+	t := &fakeTimer{clock: c, f: f}
+	if f == nil {
+		t.c = make(chan time.Time, 1)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schedule(t, d)
+	return t
+}
+
+// schedule adds the timer to fire after d.  c.mu must be held.
+func (c *FakeClock) schedule(t *fakeTimer, d time.Duration) {
+	t.when = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].when.Before(c.timers[j].when)
+	})
+	c.changed.Broadcast()
+}
+
+// unschedule removes the timer and return true if it was pending.  c.mu must be held.
+func (c *FakeClock) unschedule(t *fakeTimer) bool {
+	for i := range c.timers {
+		if c.timers[i] == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.changed.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward by d, firing the timers that come due on the way.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock forward to t, firing the timers that come due on the way.
+// Setting a time before Now only changes Now.
+func (c *FakeClock) Set(t time.Time) {
+	for {
+		c.mu.Lock()
+		if len(c.timers) == 0 || c.timers[0].when.After(t) {
+			c.now = t
+			c.mu.Unlock()
+			return
+		}
+		timer := c.timers[0]
+		c.timers = c.timers[1:]
+		if timer.when.After(c.now) {
+			c.now = timer.when
+		}
+		now := c.now
+		c.changed.Broadcast()
+		c.mu.Unlock()
+		timer.fire(now)
+	}
+}
+
+// Timers return the number of timers waiting to fire
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil waits until at least n timers are waiting to fire.  Use it to wait for a goroutine
+// under test to start waiting on the clock before advancing it.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.changed.Wait()
+	}
+}
+
+// fakeTimer is a timer created by a FakeClock
+type fakeTimer struct {
+	clock *FakeClock
+	when  time.Time
+	c     chan time.Time
+	f     func()
+}
+
+func (t *fakeTimer) fire(now time.Time) {
+	if t.f != nil {
+		t.f()
+		return
+	}
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.unschedule(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.clock.unschedule(t)
+	t.clock.schedule(t, d)
+	return active
+}
@@ -0,0 +1,104 @@
+package cronfabtest
+
+import (
+	"testing"
+	"time"
+)
+
+var clockStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClock_Advance(t *testing.T) {
+	c := NewFakeClock(clockStart)
+	timer := c.NewTimer(time.Minute)
+	c.Advance(30 * time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+	c.Advance(time.Hour)
+	if got, want := <-timer.C(), clockStart.Add(time.Minute); !got.Equal(want) {
+		t.Errorf("expected fire time %v, got %v", want, got)
+	}
+	if got, want := c.Now(), clockStart.Add(time.Hour+30*time.Second); !got.Equal(want) {
+		t.Errorf("expected now %v, got %v", want, got)
+	}
+}
+
+func TestFakeClock_AfterFuncOrder(t *testing.T) {
+	c := NewFakeClock(clockStart)
+	var got []time.Duration
+	for _, d := range []time.Duration{3 * time.Second, time.Second, 2 * time.Second} {
+		d := d
+		c.AfterFunc(d, func() {
+			if now := c.Now(); !now.Equal(clockStart.Add(d)) {
+				t.Errorf("expected now %v in callback, got %v", clockStart.Add(d), now)
+			}
+			got = append(got, d)
+		})
+	}
+	c.Advance(time.Minute)
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestFakeClock_AfterFuncReschedules(t *testing.T) {
+	c := NewFakeClock(clockStart)
+	q := 0
+	var tick func()
+	tick = func() {
+		q++
+		c.AfterFunc(time.Second, tick)
+	}
+	c.AfterFunc(time.Second, tick)
+	c.Advance(10 * time.Second)
+	if q != 10 {
+		t.Errorf("expected 10 calls, got %d", q)
+	}
+}
+
+func TestFakeClock_StopReset(t *testing.T) {
+	c := NewFakeClock(clockStart)
+	timer := c.NewTimer(time.Minute)
+	if !timer.Stop() {
+		t.Errorf("expected Stop to report an active timer")
+	}
+	if timer.Stop() {
+		t.Errorf("expected second Stop to report an inactive timer")
+	}
+	if timer.Reset(2 * time.Minute) {
+		t.Errorf("expected Reset of a stopped timer to report inactive")
+	}
+	c.Advance(time.Minute)
+	select {
+	case <-timer.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+	c.Advance(time.Minute)
+	if got, want := <-timer.C(), clockStart.Add(2*time.Minute); !got.Equal(want) {
+		t.Errorf("expected fire time %v, got %v", want, got)
+	}
+}
+
+func TestFakeClock_BlockUntil(t *testing.T) {
+	c := NewFakeClock(clockStart)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-c.NewTimer(time.Hour).C()
+	}()
+	c.BlockUntil(1)
+	c.Advance(time.Hour)
+	<-done
+	if c.Timers() != 0 {
+		t.Errorf("expected no pending timers, got %d", c.Timers())
+	}
+}
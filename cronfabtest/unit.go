@@ -0,0 +1,127 @@
+// Package cronfabtest provides helpers for testing code built on cronfab.
+package cronfabtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aalpar/cronfab"
+)
+
+// ReferenceUnits are the units TestUnit compares a unit's ordering against.
+var ReferenceUnits = []cronfab.Unit{
+	cronfab.SecondUnit{},
+	cronfab.MinuteUnit{},
+	cronfab.HourUnit{},
+	cronfab.DayUnit{},
+	cronfab.WeekOfMonth{},
+	cronfab.MonthUnit{},
+	cronfab.YearUnit{},
+}
+
+// SampleTimes returns a spread of instants useful as samples for TestUnit:
+// period boundaries, month ends, leap days and year ends.
+func SampleTimes() []time.Time {
+	return []time.Time{
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 2, 28, 23, 59, 59, 999999999, time.UTC),
+		time.Date(2020, 2, 29, 12, 30, 15, 0, time.UTC),
+		time.Date(2021, 2, 28, 6, 0, 0, 0, time.UTC),
+		time.Date(2021, 7, 4, 17, 45, 30, 500, time.UTC),
+		time.Date(2022, 10, 31, 23, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 1, 0, 0, 1, 0, time.UTC),
+		time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(2024, 6, 15, 8, 20, 0, 0, time.UTC),
+		time.Date(2025, 3, 9, 2, 30, 0, 0, time.UTC),
+	}
+}
+
+// TestUnit checks that u honours the contract of the cronfab.Unit interface
+// at each of the sample times:
+//
+//   - Trunc(t) is not after t, and Trunc is idempotent
+//   - Add(Trunc(t), 1) is after t and starts the next period, with no gap
+//     between the two periods
+//   - Less is irreflexive, and orders u consistently with the reference
+//     units: u must be Less than every unit with a longer period and not
+//     Less than any unit with a shorter one
+//
+// A unit that breaks these rules can make CrontabConfig.Next loop until it
+// runs out of iterations.
+func TestUnit(t testing.TB, u cronfab.Unit, samples []time.Time) {
+	t.Helper()
+	if u.String() == "" {
+		t.Errorf("%T: String is empty", u)
+	}
+	if u.Less(u) {
+		t.Errorf("%s: Less(%s) is true", u, u)
+	}
+	for _, s := range samples {
+		t0 := u.Trunc(s)
+		if t0.After(s) {
+			t.Errorf("%s: Trunc(%v) = %v is after the sample", u, s, t0)
+			continue
+		}
+		if t1 := u.Trunc(t0); !t1.Equal(t0) {
+			t.Errorf("%s: Trunc is not idempotent: Trunc(%v) = %v", u, t0, t1)
+		}
+		next := u.Add(t0, 1)
+		if !next.After(s) {
+			t.Errorf("%s: Add(Trunc(%v), 1) = %v is not after the sample", u, s, next)
+			continue
+		}
+		if t1 := u.Trunc(next); !t1.Equal(next) {
+			t.Errorf("%s: Add(Trunc(%v), 1) = %v does not start a period: Trunc = %v", u, s, next, t1)
+		}
+		if t1 := u.Trunc(next.Add(-time.Nanosecond)); !t1.Equal(t0) {
+			t.Errorf("%s: gap between period %v and the next period %v", u, t0, next)
+		}
+	}
+	for _, r := range ReferenceUnits {
+		if r == u {
+			continue
+		}
+		switch comparePeriods(u, r, samples) {
+		case -1:
+			if !u.Less(r) {
+				t.Errorf("%s: period is shorter than %s but Less(%s) is false", u, r, r)
+			}
+		case 1:
+			if u.Less(r) {
+				t.Errorf("%s: period is longer than %s but Less(%s) is true", u, r, r)
+			}
+		default:
+			if u.Less(r) && r.Less(u) {
+				t.Errorf("%s: Less is true in both directions with %s", u, r)
+			}
+		}
+	}
+}
+
+// comparePeriods returns -1 if the period of u is shorter than that of r at
+// every sample, 1 if it is longer at every sample, and 0 otherwise.
+func comparePeriods(u, r cronfab.Unit, samples []time.Time) int {
+	shorter, longer := 0, 0
+	for _, s := range samples {
+		pu := period(u, s)
+		pr := period(r, s)
+		if pu < pr {
+			shorter++
+		} else if pu > pr {
+			longer++
+		}
+	}
+	if len(samples) > 0 && shorter == len(samples) {
+		return -1
+	}
+	if len(samples) > 0 && longer == len(samples) {
+		return 1
+	}
+	return 0
+}
+
+// period returns the length of the period of u containing t
+func period(u cronfab.Unit, t time.Time) time.Duration {
+	t0 := u.Trunc(t)
+	return u.Add(t0, 1).Sub(t0)
+}
@@ -0,0 +1,102 @@
+package cronfabtest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aalpar/cronfab"
+)
+
+// recorder captures failures reported by the helpers under test
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestTestUnit_BuiltinUnits(t *testing.T) {
+	for _, u := range ReferenceUnits {
+		t.Run(u.String(), func(t *testing.T) {
+			TestUnit(t, u, SampleTimes())
+		})
+	}
+}
+
+// sloppyUnit truncates to the wrong boundary so that Add(Trunc(t), 1) can
+// land before t
+type sloppyUnit struct{}
+
+func (sloppyUnit) String() string { return "sloppy" }
+
+func (sloppyUnit) Less(u cronfab.Unit) bool {
+	switch u.(type) {
+	case cronfab.SecondUnit, cronfab.MinuteUnit, cronfab.HourUnit, cronfab.DayUnit, sloppyUnit:
+		return false
+	}
+	return true
+}
+
+func (sloppyUnit) Add(t time.Time, n int) time.Time {
+	return t.AddDate(0, 0, n)
+}
+
+func (sloppyUnit) Trunc(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()-2, 0, 0, 0, 0, t.Location())
+}
+
+// coarseUnit has an hourly period but claims to be coarser than a day
+type coarseUnit struct{}
+
+func (coarseUnit) String() string { return "coarse" }
+
+func (coarseUnit) Less(u cronfab.Unit) bool {
+	switch u.(type) {
+	case cronfab.SecondUnit, cronfab.MinuteUnit, cronfab.HourUnit, cronfab.DayUnit, coarseUnit:
+		return false
+	}
+	return true
+}
+
+func (coarseUnit) Add(t time.Time, n int) time.Time {
+	return cronfab.HourUnit{}.Add(t, n)
+}
+
+func (coarseUnit) Trunc(t time.Time) time.Time {
+	return cronfab.HourUnit{}.Trunc(t)
+}
+
+func TestTestUnit_ReportsBadTrunc(t *testing.T) {
+	r := &recorder{}
+	TestUnit(r, sloppyUnit{}, SampleTimes())
+	if len(r.errs) == 0 {
+		t.Fatal("expected failures for sloppyUnit")
+	}
+}
+
+func TestTestUnit_ReportsBadLess(t *testing.T) {
+	r := &recorder{}
+	TestUnit(r, coarseUnit{}, SampleTimes())
+	if len(r.errs) != 1 {
+		t.Fatalf("expected one failure for coarseUnit, got %q", r.errs)
+	}
+}
+
+func TestTestUnit_BusinessDayUnit(t *testing.T) {
+	holidays := cronfab.NewHolidayCalendar(time.Date(2021, 7, 5, 0, 0, 0, 0, time.UTC))
+	TestUnit(t, cronfab.BusinessDayUnit{Holidays: holidays}, SampleTimes())
+}
+
+func TestTestUnit_ISOWeekUnit(t *testing.T) {
+	TestUnit(t, cronfab.ISOWeekUnit{}, SampleTimes())
+}
+
+func TestTestUnit_WeekOfMonth(t *testing.T) {
+	TestUnit(t, cronfab.WeekOfMonth{FirstDay: time.Monday}, SampleTimes())
+	TestUnit(t, cronfab.WeekOfMonth{Ordinal: true}, SampleTimes())
+}
@@ -0,0 +1,336 @@
+// Package crontab reads and writes whole crontab files: comments, blank lines, environment
+// assignments and entries made of a time specification, an optional user and a command.
+package crontab
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aalpar/cronfab"
+)
+
+var (
+	ErrMissingFields  = errors.New("missing time fields")
+	ErrMissingUser    = errors.New("missing user")
+	ErrMissingCommand = errors.New("missing command")
+)
+
+// Reboot is the time specification of entries run once at startup.  It has no CrontabLine.
+const Reboot = "@reboot"
+
+// ErrorLine is returned for a line that can't be parsed
+type ErrorLine struct {
+	Line int
+	Err  error
+}
+
+func (e *ErrorLine) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ErrorLine) Unwrap() error {
+	return e.Err
+}
+
+// Kind is the kind of a line of a crontab file
+type Kind int
+
+const (
+	KindBlank = Kind(iota)
+	KindComment
+	KindEnv
+	KindEntry
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindBlank:
+		return "blank"
+	case KindComment:
+		return "comment"
+	case KindEnv:
+		return "env"
+	case KindEntry:
+		return "entry"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// File is a parsed crontab file
+type File struct {
+	Lines []*Line
+	// NoFinalNewline is true if the last line was not terminated by a newline
+	NoFinalNewline bool
+}
+
+// Line is one line of a crontab file
+type Line struct {
+	// Number is the line number, counting from 1.  Zero for lines added after parsing.
+	Number int
+	Kind   Kind
+	// Raw is the text of the line as read.  The writer writes Raw back unchanged; clear it to
+	// have the line written from its fields instead.
+	Raw string
+	// Name and Value are the variable and value of a KindEnv line
+	Name  string
+	Value string
+	// Entry is the entry of a KindEntry line
+	Entry *Entry
+}
+
+// Entry is a scheduled command
+type Entry struct {
+	// Spec is the time specification: the time fields joined by single spaces, or an alias
+	Spec string
+	// Line is the parsed time specification.  Nil for Reboot.
+	Line cronfab.CrontabLine
+	// User is the user column of system crontab files
+	User string
+	// Command is the rest of the line
+	Command string
+	// Location is the time zone set by the last CRON_TZ line before the entry, or nil
+	Location *time.Location
+	// Env is the environment assigned by the lines before the entry
+	Env map[string]string
+}
+
+// Parser parses crontab files
+type Parser struct {
+	// Config parses the time specifications.  cronfab.DefaultCrontabConfig if nil.
+	Config *cronfab.CrontabConfig
+	// UserColumn is set for system crontab files, like /etc/crontab, that have a user column
+	// between the time specification and the command.
+	UserColumn bool
+}
+
+// Parse return the parsed crontab file read from r.  Errors are *ErrorLine.
+func (p Parser) Parse(r io.Reader) (*File, error) {
+	cc := p.Config
+	if cc == nil {
+		cc = cronfab.DefaultCrontabConfig
+	}
+	f := &File{}
+	env := map[string]string{}
+	var loc *time.Location
+	br := bufio.NewReader(r)
+	for number := 1; ; number++ {
+		raw, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if raw == "" && err == io.EOF {
+			break
+		}
+		if strings.HasSuffix(raw, "\n") {
+			raw = raw[:len(raw)-1]
+		} else {
+			f.NoFinalNewline = true
+		}
+		l, perr := p.parseLine(cc, raw)
+		if perr != nil {
+			return nil, &ErrorLine{Line: number, Err: perr}
+		}
+		l.Number = number
+		switch l.Kind {
+		case KindEnv:
+			if l.Name == "CRON_TZ" {
+				loc, perr = time.LoadLocation(l.Value)
+				if perr != nil {
+					return nil, &ErrorLine{Line: number, Err: perr}
+				}
+			}
+			env[l.Name] = l.Value
+		case KindEntry:
+			l.Entry.Location = loc
+			l.Entry.Env = make(map[string]string, len(env))
+			for k, v := range env {
+				l.Entry.Env[k] = v
+			}
+		}
+		f.Lines = append(f.Lines, l)
+		if err == io.EOF {
+			break
+		}
+	}
+	return f, nil
+}
+
+// parseLine parses one line without its newline
+func (p Parser) parseLine(cc *cronfab.CrontabConfig, raw string) (*Line, error) {
+	l := &Line{Raw: raw}
+	s := strings.TrimSpace(raw)
+	switch {
+	case s == "":
+		l.Kind = KindBlank
+	case s[0] == '#':
+		l.Kind = KindComment
+	case isEnv(s):
+		l.Kind = KindEnv
+		i := strings.IndexByte(s, '=')
+		l.Name = strings.TrimSpace(s[:i])
+		l.Value = unquote(strings.TrimSpace(s[i+1:]))
+	default:
+		e, err := p.parseEntry(cc, s)
+		if err != nil {
+			return nil, err
+		}
+		l.Kind = KindEntry
+		l.Entry = e
+	}
+	return l, nil
+}
+
+// parseEntry parses an entry line with surrounding space removed
+func (p Parser) parseEntry(cc *cronfab.CrontabConfig, s string) (*Entry, error) {
+	e := &Entry{}
+	var tok string
+	if s[0] == '@' {
+		tok, s = nextToken(s)
+		e.Spec = tok
+	} else {
+		fields := make([]string, 0, len(cc.Fields))
+		for len(fields) < len(cc.Fields) {
+			tok, s = nextToken(s)
+			if tok == "" {
+				return nil, ErrMissingFields
+			}
+			fields = append(fields, tok)
+		}
+		e.Spec = strings.Join(fields, " ")
+	}
+	if e.Spec != Reboot {
+		ctl, err := cc.ParseCronTab(e.Spec)
+		if err != nil {
+			return nil, err
+		}
+		if len(ctl) != len(cc.Fields) {
+			return nil, ErrMissingFields
+		}
+		e.Line = ctl
+	}
+	if p.UserColumn {
+		tok, s = nextToken(s)
+		if tok == "" {
+			return nil, ErrMissingUser
+		}
+		e.User = tok
+	}
+	e.Command = strings.TrimSpace(s)
+	if e.Command == "" {
+		return nil, ErrMissingCommand
+	}
+	return e, nil
+}
+
+// nextToken return the first space separated token of s and the rest of s after it
+func nextToken(s string) (string, string) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// isEnv return true if s starts with a variable name followed by '='
+func isEnv(s string) bool {
+	i := strings.IndexByte(s, '=')
+	if i <= 0 {
+		return false
+	}
+	name := strings.TrimSpace(s[:i])
+	if name == "" {
+		return false
+	}
+	for j, c := range name {
+		if c != '_' && !unicode.IsLetter(c) && (j == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// unquote removes matching single or double quotes around a value
+func unquote(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// Entries return the entries of the file in order
+func (f *File) Entries() []*Entry {
+	var q []*Entry
+	for _, l := range f.Lines {
+		if l.Kind == KindEntry {
+			q = append(q, l.Entry)
+		}
+	}
+	return q
+}
+
+// Add appends an entry line.  It is written from the entry's fields.
+func (f *File) Add(e *Entry) {
+	f.Lines = append(f.Lines, &Line{Kind: KindEntry, Entry: e})
+}
+
+// WriteTo writes the file to w.  Lines that have Raw text are written unchanged, so parsing a file
+// and writing it back reproduces it byte for byte.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	var q int64
+	for i, l := range f.Lines {
+		s := l.String()
+		if i < len(f.Lines)-1 || !f.NoFinalNewline {
+			s += "\n"
+		}
+		n, err := io.WriteString(w, s)
+		q += int64(n)
+		if err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
+
+func (f *File) String() string {
+	var sb strings.Builder
+	f.WriteTo(&sb)
+	return sb.String()
+}
+
+// String return the text of the line: Raw if set, otherwise the line written from its fields
+func (l *Line) String() string {
+	if l.Raw != "" {
+		return l.Raw
+	}
+	switch l.Kind {
+	case KindEnv:
+		v := l.Value
+		if v == "" || strings.TrimSpace(v) != v {
+			v = "\"" + v + "\""
+		}
+		return l.Name + "=" + v
+	case KindEntry:
+		if l.Entry != nil {
+			return l.Entry.String()
+		}
+	}
+	return ""
+}
+
+// String return the entry as a crontab line
+func (e *Entry) String() string {
+	s := e.Spec
+	if s == "" && e.Line != nil {
+		s = e.Line.String()
+	}
+	if e.User != "" {
+		s += " " + e.User
+	}
+	return s + " " + e.Command
+}
@@ -0,0 +1,166 @@
+package crontab
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aalpar/cronfab"
+)
+
+const userCrontab = `# m h dom mon dow command
+SHELL=/bin/bash
+MAILTO = "ops@example.com"
+
+*/15 * * * *   /usr/local/bin/poll --quiet  >/dev/null 2>&1
+CRON_TZ=America/New_York
+30 4 1 jan,jul *	backup.sh % weekly
+  @daily  rotate-logs
+@reboot start-agent
+`
+
+func TestParse(t *testing.T) {
+	f, err := Parser{}.Parse(strings.NewReader(userCrontab))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := []Kind{KindComment, KindEnv, KindEnv, KindBlank, KindEntry, KindEnv, KindEntry, KindEntry, KindEntry}
+	if len(f.Lines) != len(kinds) {
+		t.Fatalf("expected %d lines, got %d", len(kinds), len(f.Lines))
+	}
+	for i, l := range f.Lines {
+		if l.Kind != kinds[i] || l.Number != i+1 {
+			t.Errorf("line %d: expected %v, got %v numbered %d", i+1, kinds[i], l.Kind, l.Number)
+		}
+	}
+	if l := f.Lines[2]; l.Name != "MAILTO" || l.Value != "ops@example.com" {
+		t.Errorf("unexpected env %q=%q", l.Name, l.Value)
+	}
+
+	entries := f.Entries()
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Spec != "*/15 * * * *" || e.Command != "/usr/local/bin/poll --quiet  >/dev/null 2>&1" || e.Location != nil {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e.Env["SHELL"] != "/bin/bash" || e.Env["MAILTO"] != "ops@example.com" {
+		t.Errorf("unexpected env %v", e.Env)
+	}
+	want, _ := cronfab.DefaultCrontabConfig.ParseCronTab("*/15 * * * *")
+	if e.Line.String() != want.String() {
+		t.Errorf("expected line %v, got %v", want, e.Line)
+	}
+
+	e = entries[1]
+	if e.Spec != "30 4 1 jan,jul *" || e.Command != "backup.sh % weekly" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e.Location == nil || e.Location.String() != "America/New_York" || e.Env["CRON_TZ"] != "America/New_York" {
+		t.Errorf("expected the CRON_TZ location, got %v", e.Location)
+	}
+	if e := entries[2]; e.Spec != "@daily" || e.Line == nil || e.Command != "rotate-logs" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e := entries[3]; e.Spec != Reboot || e.Line != nil || e.Command != "start-agent" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	for _, s := range []string{
+		userCrontab,
+		strings.TrimSuffix(userCrontab, "\n"),
+		"# windows\r\n0 0 * * * cmd\r\n",
+		"",
+	} {
+		f, err := Parser{}.Parse(strings.NewReader(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := f.String(); got != s {
+			t.Errorf("expected %q, got %q", s, got)
+		}
+	}
+}
+
+func TestParse_UserColumn(t *testing.T) {
+	const system = "SHELL=/bin/sh\n17 * * * * root cd / && run-parts --report /etc/cron.hourly\n"
+	f, err := Parser{UserColumn: true}.Parse(strings.NewReader(system))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := f.Entries()[0]
+	if e.User != "root" || e.Command != "cd / && run-parts --report /etc/cron.hourly" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if _, err := (Parser{UserColumn: true}).Parse(strings.NewReader("17 * * * * root\n")); !errors.Is(err, ErrMissingCommand) {
+		t.Errorf("expected %v, got %v", ErrMissingCommand, err)
+	}
+	if _, err := (Parser{UserColumn: true}).Parse(strings.NewReader("@hourly\n")); !errors.Is(err, ErrMissingUser) {
+		t.Errorf("expected %v, got %v", ErrMissingUser, err)
+	}
+}
+
+func TestParse_Config(t *testing.T) {
+	p := Parser{Config: cronfab.SecondCrontabConfig}
+	f, err := p.Parse(strings.NewReader("0 */5 * * * * * ping\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := f.Entries()[0]; e.Spec != "0 */5 * * * * *" || len(e.Line) != 7 || e.Command != "ping" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		line int
+		err  error
+	}{
+		{"# ok\n* * * *\n", 2, ErrMissingFields},
+		{"\n\n* * * * *\n", 3, ErrMissingCommand},
+		{"CRON_TZ=Nowhere/Special\n", 1, nil},
+		{"* * * * *  cmd\n61 * * * * cmd\n", 2, nil},
+		{"@fortnightly cmd\n", 1, nil},
+	} {
+		_, err := Parser{}.Parse(strings.NewReader(tc.s))
+		var el *ErrorLine
+		if !errors.As(err, &el) {
+			t.Errorf("%q: expected a line error, got %v", tc.s, err)
+			continue
+		}
+		if el.Line != tc.line {
+			t.Errorf("%q: expected line %d, got %d", tc.s, tc.line, el.Line)
+		}
+		if tc.err != nil && !errors.Is(err, tc.err) {
+			t.Errorf("%q: expected %v, got %v", tc.s, tc.err, err)
+		}
+		if !strings.HasPrefix(err.Error(), "line ") {
+			t.Errorf("%q: expected the line number in %q", tc.s, err.Error())
+		}
+	}
+}
+
+func TestFile_Edit(t *testing.T) {
+	f, err := Parser{}.Parse(strings.NewReader("# jobs\nPATH=/bin\n0 0 * * * nightly\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := f.Entries()[0]
+	e.Command = "nightly --full"
+	f.Lines[2].Raw = ""
+	f.Lines[1].Raw = ""
+	f.Lines[1].Value = "/usr/bin:/bin"
+	f.Add(&Entry{Spec: "@hourly", Command: "tick"})
+	f.Lines = append(f.Lines, &Line{Kind: KindEnv, Name: "EMPTY"})
+	want := "# jobs\nPATH=/usr/bin:/bin\n0 0 * * * nightly --full\n@hourly tick\nEMPTY=\"\"\n"
+	if got := f.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := (&Entry{Spec: "@hourly", User: "root", Command: "tick"}).String(); got != "@hourly root tick" {
+		t.Errorf("unexpected %q", got)
+	}
+}
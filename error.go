@@ -2,6 +2,8 @@ package cronfab
 
 import (
 	"fmt"
+	"strings"
+	"time"
 )
 
 type ErrorBadIndex struct {
@@ -30,3 +32,36 @@ type ErrorParse struct {
 func (q *ErrorParse) Error() string {
 	return fmt.Sprintf("%s at %d", StateString(q.State), q.Index)
 }
+
+// ErrorSearchLimit is returned by Next when the search gives up before finding a match.
+// Err is ErrMaxit or ErrMaxSpan.
+type ErrorSearchLimit struct {
+	Err        error
+	Start      time.Time
+	Reached    time.Time
+	Iterations int
+}
+
+func (q *ErrorSearchLimit) Error() string {
+	return fmt.Sprintf("%v: searched from %s to %s in %d iterations", q.Err, q.Start.Format(time.RFC3339), q.Reached.Format(time.RFC3339), q.Iterations)
+}
+
+// Unwrap return the limit that was met so that errors.Is(err, ErrMaxit) works
+func (q *ErrorSearchLimit) Unwrap() error {
+	return q.Err
+}
+
+// ErrorUnsatisfiable is returned by Satisfiable when no instant matches a crontab line.
+// Fields names the conflicting fields.
+type ErrorUnsatisfiable struct {
+	Fields []string
+}
+
+func (q *ErrorUnsatisfiable) Error() string {
+	return fmt.Sprintf("%v: conflicting fields %s", ErrUnsatisfiable, strings.Join(q.Fields, ", "))
+}
+
+// Unwrap return ErrUnsatisfiable so that errors.Is works
+func (q *ErrorUnsatisfiable) Unwrap() error {
+	return ErrUnsatisfiable
+}
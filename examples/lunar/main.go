@@ -12,7 +12,6 @@ package main
 
 import (
 	"fmt"
-	"math"
 	"time"
 
 	"github.com/aalpar/cronfab"
@@ -21,49 +20,49 @@ import (
 // lunarEpoch is a known new moon (January 6, 2000 at 18:14 UTC).
 var lunarEpoch = time.Date(2000, 1, 6, 18, 14, 0, 0, time.UTC)
 
-// synodicPeriod is the average length of a synodic month in days.
-const synodicPeriod = 29.53059
+// synodicPeriod is the average length of a synodic month (29.53059 days).
+const synodicPeriod = time.Duration(29.53059 * 24 * float64(time.Hour))
 
-// phasePeriod is the average length of a single moon phase in days (1/8 of a synodic month).
+// phasePeriod is the average length of a single moon phase (1/8 of a synodic month).
 const phasePeriod = synodicPeriod / 8
 
 // MoonPhaseUnit represents the phase of the moon within a synodic month.
-// The synodic month is divided into 8 phases (0-7).
+// The synodic month is divided into 8 phases (0-7).  Phase arithmetic is
+// done in whole nanoseconds so that Trunc is exact and idempotent.
 type MoonPhaseUnit struct{}
 
 func (MoonPhaseUnit) String() string { return "moon phase" }
 
 func (MoonPhaseUnit) Add(t time.Time, n int) time.Time {
-	hours := phasePeriod * float64(n) * 24
-	return t.Add(time.Duration(hours * float64(time.Hour)))
+	return t.Add(time.Duration(n) * phasePeriod)
 }
 
 func (MoonPhaseUnit) Less(u cronfab.Unit) bool {
 	switch u.(type) {
 	case cronfab.SecondUnit, cronfab.MinuteUnit, cronfab.HourUnit,
-		cronfab.DayUnit, cronfab.WeekOfMonth, MoonPhaseUnit:
+		cronfab.DayUnit, MoonPhaseUnit:
 		return false
 	}
 	return true
 }
 
 func (MoonPhaseUnit) Trunc(t time.Time) time.Time {
-	diff := t.Sub(lunarEpoch).Hours() / 24
-	phaseDays := math.Mod(diff, phasePeriod)
-	if phaseDays < 0 {
-		phaseDays += phasePeriod
+	return t.Add(-sinceEpoch(t, phasePeriod))
+}
+
+// sinceEpoch returns how far t is into the current period of length p,
+// counting periods from lunarEpoch.
+func sinceEpoch(t time.Time, p time.Duration) time.Duration {
+	d := t.Sub(lunarEpoch) % p
+	if d < 0 {
+		d += p
 	}
-	return t.Add(-time.Duration(phaseDays * 24 * float64(time.Hour)))
+	return d
 }
 
 // moonPhaseIndex returns the current moon phase (0-7) for a given time.
 func moonPhaseIndex(t time.Time) int {
-	diff := t.Sub(lunarEpoch).Hours() / 24
-	cycleDays := math.Mod(diff, synodicPeriod)
-	if cycleDays < 0 {
-		cycleDays += synodicPeriod
-	}
-	return int(cycleDays / phasePeriod)
+	return int(sinceEpoch(t, synodicPeriod) / phasePeriod)
 }
 
 var lunarConfig = cronfab.MustCrontabConfig([]cronfab.FieldConfig{
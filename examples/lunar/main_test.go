@@ -0,0 +1,11 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aalpar/cronfab/cronfabtest"
+)
+
+func TestMoonPhaseUnit(t *testing.T) {
+	cronfabtest.TestUnit(t, MoonPhaseUnit{}, cronfabtest.SampleTimes())
+}
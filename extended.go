@@ -0,0 +1,100 @@
+package cronfab
+
+import (
+	"time"
+)
+
+// DayOfYearField is the day of the year, from 1 to 366.  Day 366 is December 31 of a leap year
+// and only matches then.
+var DayOfYearField = FieldConfig{
+	Unit: DayUnit{},
+	Name: "day of year",
+	Min:  1,
+	Max:  366,
+	GetIndex: func(t time.Time) int {
+		return t.YearDay()
+	},
+}
+
+// ISOWeekField is the ISO 8601 week of the year, from 1 to 53.  Weeks start on Monday, and week 1
+// is the week with the year's first Thursday, so the first days of January can be in week 52 or
+// 53 of the year before and the last days of December in week 1 of the year after.  Week 53 only
+// matches in the years that have one.
+var ISOWeekField = FieldConfig{
+	Unit: ISOWeekUnit{},
+	Name: "iso week",
+	Min:  1,
+	Max:  53,
+	GetIndex: func(t time.Time) int {
+		_, w := t.ISOWeek()
+		return w
+	},
+}
+
+// ExtendedCrontabConfig is DefaultCrontabConfig with two more fields: day of year and ISO week.
+// "0 9 * * mon * */2" is 09:00 on Monday of every odd ISO week and "0 0 * * * 100 *" is midnight
+// on day 100 of the year.
+var ExtendedCrontabConfig = MustCrontabConfig([]FieldConfig{
+	{
+		Unit: MinuteUnit{},
+		Name: "minute",
+		Min:  0,
+		Max:  59,
+		GetIndex: func(t time.Time) int {
+			return t.Minute()
+		},
+	},
+	{
+		Unit: HourUnit{},
+		Name: "hour",
+		Min:  0,
+		Max:  23,
+		GetIndex: func(t time.Time) int {
+			return t.Hour()
+		},
+	},
+	{
+		Unit: DayUnit{},
+		Name: "day of month",
+		Min:  1,
+		Max:  31,
+		GetIndex: func(t time.Time) int {
+			return t.Day()
+		},
+	},
+	{
+		Unit:       MonthUnit{},
+		Name:       "month",
+		RangeNames: []string{"january", "february", "march", "april", "may", "june", "july", "august", "september", "october", "november", "december"},
+		Min:        1,
+		Max:        12,
+		GetIndex: func(t time.Time) int {
+			return int(t.Month())
+		},
+	},
+	{
+		Unit:       DayUnit{},
+		Name:       "day of week",
+		RangeNames: []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"},
+		Min:        0,
+		Max:        6,
+		GetIndex: func(t time.Time) int {
+			return int(t.Weekday())
+		},
+	},
+	DayOfYearField,
+	ISOWeekField,
+})
+
+func init() {
+	ExtendedCrontabConfig.Name = "extended"
+	ExtendedCrontabConfig.Aliases = map[string]string{
+		"@yearly":   "0 0 1 1 * * *",
+		"@annually": "0 0 1 1 * * *",
+		"@monthly":  "0 0 1 * * * *",
+		"@weekly":   "0 0 * * 0 * *",
+		"@daily":    "0 0 * * * * *",
+		"@midnight": "0 0 * * * * *",
+		"@hourly":   "0 * * * * * *",
+	}
+}
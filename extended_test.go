@@ -0,0 +1,63 @@
+package cronfab
+
+import (
+	"testing"
+	"time"
+)
+
+func TestISOWeekField(t *testing.T) {
+	for _, tc := range []struct {
+		t    time.Time
+		want int
+	}{
+		{time.Date(2020, 12, 31, 12, 0, 0, 0, time.UTC), 53},
+		{time.Date(2021, 1, 3, 23, 59, 0, 0, time.UTC), 53},
+		{time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2024, 12, 29, 0, 0, 0, 0, time.UTC), 52},
+		{time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), 1},
+	} {
+		if got := ISOWeekField.GetIndex(tc.t); got != tc.want {
+			t.Errorf("%v: expected week %d, got %d", tc.t, tc.want, got)
+		}
+	}
+	if got, want := (ISOWeekUnit{}).Trunc(time.Date(2021, 1, 3, 13, 0, 0, 0, time.UTC)), time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestExtendedCrontabConfig(t *testing.T) {
+	cc := ExtendedCrontabConfig
+	want := []string{"minute", "hour", "day", "iso week", "month"}
+	for i, u := range cc.Units {
+		if u.String() != want[i] {
+			t.Errorf("%d: expected %s, got %s", i, want[i], u)
+		}
+	}
+	at := func(y int, m time.Month, d, h int) time.Time {
+		return time.Date(y, m, d, h, 0, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		line  string
+		start time.Time
+		want  []time.Time
+	}{
+		// 2024 has 52 weeks, so week 51 is followed by week 1 of 2025 on December 30
+		{"0 9 * * mon * */2", at(2024, 12, 10, 0), []time.Time{at(2024, 12, 16, 9), at(2024, 12, 30, 9), at(2025, 1, 13, 9)}},
+		// 2020 has 53 weeks, the last of which runs into 2021
+		{"0 9 * * * * 53", at(2020, 12, 31, 12), []time.Time{at(2021, 1, 1, 9), at(2021, 1, 2, 9), at(2021, 1, 3, 9), at(2026, 12, 28, 9)}},
+		{"0 0 * * * 100 *", at(2024, 1, 1, 0), []time.Time{at(2024, 4, 9, 0), at(2025, 4, 10, 0)}},
+		{"0 0 * * * 366 *", at(2025, 1, 1, 0), []time.Time{at(2028, 12, 31, 0)}},
+		{"@weekly", at(2025, 1, 1, 0), []time.Time{at(2025, 1, 5, 0)}},
+	} {
+		cl, err := cc.Compile(mustLine(t, cc, tc.line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := collect(t, cl, tc.start, len(tc.want))
+		for i := range tc.want {
+			if !got[i].Equal(tc.want[i]) {
+				t.Errorf("%q %d: expected %v, got %v", tc.line, i, tc.want[i], got[i])
+			}
+		}
+	}
+}
@@ -23,3 +23,13 @@ func (configField FieldConfig) Ceil(tabField CrontabField, t time.Time) (time.Ti
 	}
 	return configField.Unit.Add(t, x1-x0), false
 }
+
+// CeilSet is like Ceil for a compiled crontab field
+func (configField FieldConfig) CeilSet(fs FieldSet, t time.Time) (time.Time, bool) {
+	x0 := configField.GetIndex(t)
+	x1, roll := fs.Ceil(x0)
+	if roll {
+		return t, true
+	}
+	return configField.Unit.Add(t, x1-x0), false
+}
@@ -0,0 +1,77 @@
+package cronfab
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// weekdayNames are the names of the weekdays from Sunday
+var weekdayNames = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// NewDayOfWeekField return a field of the day of the week that counts from first, so that its
+// ranges run from first to the day before it.  The days are numbered from int(first), which keeps
+// the usual numbers of first and the days after it up to Saturday; the days after Saturday carry
+// on from 7.  With first Monday the days are 1 (Monday) to 7 (Sunday), as in ISO 8601, and
+// "mon-sun" is every day.
+func NewDayOfWeekField(first time.Weekday) FieldConfig {
+	return dayOfWeekField("day of week", weekdayNames, time.Sunday, first)
+}
+
+// dayOfWeekField return a day of week field named name that counts from first.  names are the
+// names of the days from the weekday from.
+func dayOfWeekField(name string, names []string, from, first time.Weekday) FieldConfig {
+	rotated := make([]string, 7)
+	for i := range rotated {
+		rotated[i] = names[(daysSince(first, from)+i)%7]
+	}
+	return FieldConfig{
+		Unit:       DayUnit{},
+		Name:       name,
+		RangeNames: rotated,
+		Min:        int(first),
+		Max:        int(first) + 6,
+		GetIndex: func(t time.Time) int {
+			return int(first) + daysSince(t.Weekday(), first)
+		},
+	}
+}
+
+// WithFirstDay return a copy of the config whose weeks start on first.  The day of week field, one
+// named "day of week" with seven days, counts from first as NewDayOfWeekField does, week of month
+// fields whose rows start on another day become NewWeekOfMonthField(first), and "@weekly" fires at
+// the start of first.  The copy's name
+// is the config's name followed by the day, like "default-monday", unless first is Sunday.
+func (cc *CrontabConfig) WithFirstDay(first time.Weekday) *CrontabConfig {
+	fields := make([]FieldConfig, len(cc.Fields))
+	dow := -1
+	for i, f := range cc.Fields {
+		fields[i] = f
+		switch u := f.Unit.(type) {
+		case DayUnit:
+			if f.Name == "day of week" && f.Max-f.Min == 6 && len(f.RangeNames) == 7 {
+				fields[i] = dayOfWeekField(f.Name, f.RangeNames, time.Weekday(f.Min%7), first)
+				dow = i
+			}
+		case WeekOfMonth:
+			if !u.Ordinal && u.FirstDay != first {
+				fields[i] = NewWeekOfMonthField(first)
+				fields[i].Name = f.Name
+			}
+		}
+	}
+	q := cc.Copy()
+	fc := MustCrontabConfig(fields)
+	q.Fields, q.FieldUnits, q.Units = fc.Fields, fc.FieldUnits, fc.Units
+	if first != time.Sunday {
+		q.Name += "-" + strings.ToLower(first.String())
+	}
+	if expr, ok := q.Aliases["@weekly"]; ok && dow >= 0 {
+		parts := strings.Fields(expr)
+		if len(parts) == len(fields) {
+			parts[dow] = strconv.Itoa(int(first))
+			q.Aliases["@weekly"] = strings.Join(parts, " ")
+		}
+	}
+	return q
+}
@@ -0,0 +1,73 @@
+package cronfab
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewDayOfWeekField(t *testing.T) {
+	f := NewDayOfWeekField(time.Monday)
+	if f.Min != 1 || f.Max != 7 || f.RangeNames[0] != "monday" || f.RangeNames[6] != "sunday" {
+		t.Errorf("unexpected field %+v", f)
+	}
+	for _, tc := range []struct {
+		t    time.Time
+		want int
+	}{
+		{time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC), 6},
+		{time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC), 7},
+	} {
+		if got := f.GetIndex(tc.t); got != tc.want {
+			t.Errorf("%v: expected %d, got %d", tc.t, tc.want, got)
+		}
+	}
+}
+
+func TestWithFirstDay(t *testing.T) {
+	cc := DefaultCrontabConfig.WithFirstDay(time.Monday)
+	if cc.Name != "default-monday" {
+		t.Errorf("unexpected name %q", cc.Name)
+	}
+	if DefaultCrontabConfig.Aliases["@weekly"] != "0 0 * * 0" {
+		t.Errorf("DefaultCrontabConfig changed: %q", DefaultCrontabConfig.Aliases["@weekly"])
+	}
+	at := func(d, h int) time.Time {
+		return time.Date(2025, 1, d, h, 0, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		cc    *CrontabConfig
+		line  string
+		start time.Time
+		want  []time.Time
+	}{
+		{cc, "0 9 * * mon-sun", at(3, 12), []time.Time{at(4, 9), at(5, 9), at(6, 9)}},
+		{cc, "0 9 * * sat-sun", at(3, 12), []time.Time{at(4, 9), at(5, 9), at(11, 9)}},
+		{cc, "0 9 * * 7", at(3, 12), []time.Time{at(5, 9), at(12, 9)}},
+		{cc, "@weekly", at(1, 0), []time.Time{at(6, 0), at(13, 0)}},
+		{KubernetesCrontabConfig.WithFirstDay(time.Monday), "0 9 * * fri-sun", at(1, 0), []time.Time{at(3, 9), at(4, 9), at(5, 9), at(10, 9)}},
+		// rows start on Monday, so the second row of February 2025 starts on the 3rd
+		{SecondCrontabConfig.WithFirstDay(time.Monday), "0 0 0 * 2 feb *", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), []time.Time{
+			time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC),
+		}},
+	} {
+		cl, err := tc.cc.Compile(mustLine(t, tc.cc, tc.line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := collect(t, cl, tc.start, len(tc.want))
+		for i := range tc.want {
+			if !got[i].Equal(tc.want[i]) {
+				t.Errorf("%s %q %d: expected %v, got %v", tc.cc.Name, tc.line, i, tc.want[i], got[i])
+			}
+		}
+	}
+
+	same := DefaultCrontabConfig.WithFirstDay(time.Sunday)
+	if same.Name != "default" || same.Aliases["@weekly"] != "0 0 * * 0" {
+		t.Errorf("unexpected config %q %q", same.Name, same.Aliases["@weekly"])
+	}
+	if _, err := same.ParseCronTab("0 9 * * sun-sat"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,125 @@
+package fiscal
+
+import (
+	"time"
+
+	"github.com/aalpar/cronfab"
+)
+
+// Field names of the fields of NewConfig
+const (
+	FieldMinute      = "minute"
+	FieldHour        = "hour"
+	FieldDayOfPeriod = "day of fiscal period"
+	FieldWeek        = "fiscal week"
+	FieldPeriod      = "fiscal period"
+	FieldQuarter     = "fiscal quarter"
+	FieldYear        = "fiscal year"
+)
+
+// Year range of YearField
+const (
+	MinYear = 1970
+	MaxYear = 2199
+)
+
+// YearField return a field of the fiscal year, numbered by the calendar year of its start date
+func (c Calendar) YearField() cronfab.FieldConfig {
+	return cronfab.FieldConfig{
+		Unit: YearUnit{Calendar: c},
+		Name: FieldYear,
+		Min:  MinYear,
+		Max:  MaxYear,
+		GetIndex: func(t time.Time) int {
+			y, _, _ := c.Year(t)
+			return y
+		},
+	}
+}
+
+// QuarterField return a field of the fiscal quarter, from 1 to 4
+func (c Calendar) QuarterField() cronfab.FieldConfig {
+	return cronfab.FieldConfig{
+		Unit: QuarterUnit{Calendar: c},
+		Name: FieldQuarter,
+		Min:  1,
+		Max:  4,
+		GetIndex: func(t time.Time) int {
+			return c.index(quarter, t)
+		},
+	}
+}
+
+// PeriodField return a field of the fiscal period, from 1 to 12
+func (c Calendar) PeriodField() cronfab.FieldConfig {
+	return cronfab.FieldConfig{
+		Unit: PeriodUnit{Calendar: c},
+		Name: FieldPeriod,
+		Min:  1,
+		Max:  12,
+		GetIndex: func(t time.Time) int {
+			return c.index(period, t)
+		},
+	}
+}
+
+// WeekField return a field of the fiscal week of the year, from 1 to 53
+func (c Calendar) WeekField() cronfab.FieldConfig {
+	return cronfab.FieldConfig{
+		Unit: WeekUnit{Calendar: c},
+		Name: FieldWeek,
+		Min:  1,
+		Max:  53,
+		GetIndex: func(t time.Time) int {
+			return c.index(week, t)
+		},
+	}
+}
+
+// DayOfPeriodField return a field of the day of the fiscal period, from 1 to 42.  Periods are 4 to
+// 6 weeks long.
+func (c Calendar) DayOfPeriodField() cronfab.FieldConfig {
+	return cronfab.FieldConfig{
+		Unit: cronfab.DayUnit{},
+		Name: FieldDayOfPeriod,
+		Min:  1,
+		Max:  42,
+		GetIndex: func(t time.Time) int {
+			_, off := c.locate(period, t)
+			return off + 1
+		},
+	}
+}
+
+// NewConfig return a config of the calendar with the fields minute, hour, day of fiscal period,
+// fiscal week, fiscal period, fiscal quarter and fiscal year.  "0 6 1 * * * *" is 06:00 on the
+// first day of each fiscal period.
+func NewConfig(c Calendar) *cronfab.CrontabConfig {
+	cc := cronfab.MustCrontabConfig([]cronfab.FieldConfig{
+		{
+			Unit: cronfab.MinuteUnit{},
+			Name: FieldMinute,
+			Min:  0,
+			Max:  59,
+			GetIndex: func(t time.Time) int {
+				return t.Minute()
+			},
+		},
+		{
+			Unit: cronfab.HourUnit{},
+			Name: FieldHour,
+			Min:  0,
+			Max:  23,
+			GetIndex: func(t time.Time) int {
+				return t.Hour()
+			},
+		},
+		c.DayOfPeriodField(),
+		c.WeekField(),
+		c.PeriodField(),
+		c.QuarterField(),
+		c.YearField(),
+	})
+	cc.Name = "fiscal"
+	return cc
+}
@@ -0,0 +1,213 @@
+// Package fiscal provides cronfab units and fields for retail fiscal calendars, like the 4-5-4
+// calendar of the National Retail Federation.  A fiscal year is 52 weeks, or 53 every five or six
+// years, starting on the same weekday each year.  Its four quarters are 13 weeks, each split into
+// three periods by a pattern like 4-4-5.  The 53rd week, when there is one, goes to the last period
+// of the year.
+package fiscal
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrBadPattern = errors.New("pattern must split a 13 week quarter into three periods of at least one week")
+	ErrBadStart   = errors.New("year start must be a valid day of the year")
+)
+
+// Pattern is the number of weeks in each of the three periods of a quarter
+type Pattern [3]int
+
+// Common patterns
+var (
+	Pattern445 = Pattern{4, 4, 5}
+	Pattern454 = Pattern{4, 5, 4}
+	Pattern544 = Pattern{5, 4, 4}
+)
+
+// Rule is how a year start is found from its date
+type Rule int
+
+const (
+	// Nearest starts the year on the weekday nearest the date
+	Nearest Rule = iota
+	// OnOrBefore starts the year on the last weekday on or before the date
+	OnOrBefore
+	// OnOrAfter starts the year on the first weekday on or after the date
+	OnOrAfter
+)
+
+// YearStart is the rule for the first day of a fiscal year, like the Sunday nearest February 1.
+// The fiscal year is numbered by the calendar year of its date.
+type YearStart struct {
+	Month   time.Month
+	Day     int
+	Weekday time.Weekday
+	Rule    Rule
+}
+
+// Calendar is a fiscal calendar
+type Calendar struct {
+	Pattern Pattern
+	Start   YearStart
+}
+
+// NRF is the 4-5-4 calendar of the National Retail Federation, with years starting on the Sunday
+// nearest February 1.  For a 4-4-5 calendar with the same years, use NewCalendar(Pattern445,
+// NRF.Start).
+var NRF = Calendar{
+	Pattern: Pattern454,
+	Start:   YearStart{Month: time.February, Day: 1, Weekday: time.Sunday, Rule: Nearest},
+}
+
+// NewCalendar return a calendar with the pattern and year start, or an error if either is invalid
+func NewCalendar(pattern Pattern, start YearStart) (Calendar, error) {
+	if pattern[0] < 1 || pattern[1] < 1 || pattern[2] < 1 || pattern[0]+pattern[1]+pattern[2] != 13 {
+		return Calendar{}, ErrBadPattern
+	}
+	// the day must be in the month every year, so February 29 is not allowed
+	valid := start.Month >= time.January && start.Month <= time.December && start.Day >= 1 &&
+		time.Date(2001, start.Month, start.Day, 0, 0, 0, 0, time.UTC).Day() == start.Day
+	if !valid || start.Weekday < time.Sunday || start.Weekday > time.Saturday || start.Rule < Nearest || start.Rule > OnOrAfter {
+		return Calendar{}, ErrBadStart
+	}
+	return Calendar{Pattern: pattern, Start: start}, nil
+}
+
+// StartOf return the first day of fiscal year y in loc
+func (c Calendar) StartOf(y int, loc *time.Location) time.Time {
+	s := c.Start
+	d := time.Date(y, s.Month, s.Day, 0, 0, 0, 0, loc)
+	after := (int(s.Weekday) - int(d.Weekday()) + 7) % 7
+	switch {
+	case s.Rule == OnOrAfter, s.Rule == Nearest && after <= 3:
+		return d.AddDate(0, 0, after)
+	case after == 0:
+		return d
+	}
+	return d.AddDate(0, 0, after-7)
+}
+
+// Year return the fiscal year of t, its first day and its number of weeks, reckoned in the time
+// zone of t
+func (c Calendar) Year(t time.Time) (y int, start time.Time, weeks int) {
+	y = t.Year()
+	start = c.StartOf(y, t.Location())
+	if t.Before(start) {
+		y--
+		start = c.StartOf(y, t.Location())
+	} else if next := c.StartOf(y+1, t.Location()); !t.Before(next) {
+		y++
+		start = next
+	}
+	return y, start, daysBetween(start, c.StartOf(y+1, t.Location())) / 7
+}
+
+// daysBetween return the number of calendar days from the day of a to the day of b
+func daysBetween(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua) / (24 * time.Hour))
+}
+
+// kind is a division of the fiscal year
+type kind int
+
+const (
+	year kind = iota
+	quarter
+	period
+	week
+)
+
+// segments return the number of weeks in each division of kind k of a year of the given weeks
+func (c Calendar) segments(k kind, weeks int) []int {
+	var q []int
+	switch k {
+	case year:
+		return []int{weeks}
+	case quarter:
+		q = []int{13, 13, 13, 13}
+	case period:
+		for i := 0; i < 4; i++ {
+			q = append(q, c.Pattern[:]...)
+		}
+	case week:
+		q = make([]int, 52)
+		for i := range q {
+			q[i] = 1
+		}
+	}
+	// the extra week of a long year goes to the last division
+	if extra := weeks - 52; extra > 0 {
+		if k == week {
+			for ; extra > 0; extra-- {
+				q = append(q, 1)
+			}
+		} else {
+			q[len(q)-1] += extra
+		}
+	}
+	return q
+}
+
+// position is where a time falls in a division of the fiscal year
+type position struct {
+	year  int
+	start time.Time // first day of the year
+	segs  []int     // weeks of each division of the year
+	index int       // division of the time, from 0
+	week  int       // week of the year the division starts in, from 0
+}
+
+// locate return the division of kind k that t falls in, and how many days t is into it
+func (c Calendar) locate(k kind, t time.Time) (position, int) {
+	y, start, weeks := c.Year(t)
+	p := position{year: y, start: start, segs: c.segments(k, weeks)}
+	days := daysBetween(start, t)
+	for p.index < len(p.segs)-1 && 7*(p.week+p.segs[p.index]) <= days {
+		p.week += p.segs[p.index]
+		p.index++
+	}
+	return p, days - 7*p.week
+}
+
+// index return the division of kind k that t falls in, from 1
+func (c Calendar) index(k kind, t time.Time) int {
+	p, _ := c.locate(k, t)
+	return p.index + 1
+}
+
+// trunc return the first day of the division of kind k that t falls in
+func (c Calendar) trunc(k kind, t time.Time) time.Time {
+	p, _ := c.locate(k, t)
+	return p.start.AddDate(0, 0, 7*p.week)
+}
+
+// add moves t by n divisions of kind k, keeping its day within the division, or using the last day
+// of a shorter division, and its time of day
+func (c Calendar) add(k kind, t time.Time, n int) time.Time {
+	p, off := c.locate(k, t)
+	p.index += n
+	for p.index >= len(p.segs) {
+		p.index -= len(p.segs)
+		p.year++
+		p.start = c.StartOf(p.year, t.Location())
+		p.segs = c.segments(k, daysBetween(p.start, c.StartOf(p.year+1, t.Location()))/7)
+	}
+	for p.index < 0 {
+		p.year--
+		p.start = c.StartOf(p.year, t.Location())
+		p.segs = c.segments(k, daysBetween(p.start, c.StartOf(p.year+1, t.Location()))/7)
+		p.index += len(p.segs)
+	}
+	p.week = 0
+	for _, w := range p.segs[:p.index] {
+		p.week += w
+	}
+	if last := 7*p.segs[p.index] - 1; off > last {
+		off = last
+	}
+	d := p.start.AddDate(0, 0, 7*p.week+off)
+	return time.Date(d.Year(), d.Month(), d.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
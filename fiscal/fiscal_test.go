@@ -0,0 +1,181 @@
+package fiscal
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aalpar/cronfab"
+	"github.com/aalpar/cronfab/cronfabtest"
+)
+
+// retail445 is a 4-4-5 calendar with the years of NRF
+var retail445 = Calendar{Pattern: Pattern445, Start: NRF.Start}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestUnits(t *testing.T) {
+	for _, c := range []Calendar{NRF, retail445, {Pattern: Pattern544, Start: YearStart{Month: time.September, Day: 1, Weekday: time.Monday, Rule: OnOrBefore}}} {
+		for _, u := range []cronfab.Unit{WeekUnit{Calendar: c}, PeriodUnit{Calendar: c}, QuarterUnit{Calendar: c}, YearUnit{Calendar: c}} {
+			cronfabtest.TestUnit(t, u, cronfabtest.SampleTimes())
+		}
+	}
+}
+
+func TestCalendar_Year(t *testing.T) {
+	for _, tc := range []struct {
+		t     time.Time
+		year  int
+		start time.Time
+		weeks int
+	}{
+		{date(2023, 1, 28), 2022, date(2022, 1, 30), 52},
+		{date(2023, 1, 29), 2023, date(2023, 1, 29), 53},
+		{date(2024, 2, 3), 2023, date(2023, 1, 29), 53},
+		{date(2024, 2, 4), 2024, date(2024, 2, 4), 52},
+		{date(2025, 12, 31), 2025, date(2025, 2, 2), 52},
+	} {
+		y, start, weeks := NRF.Year(tc.t)
+		if y != tc.year || !start.Equal(tc.start) || weeks != tc.weeks {
+			t.Errorf("%v: expected %d %v %d, got %d %v %d", tc.t, tc.year, tc.start, tc.weeks, y, start, weeks)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	cc := NewConfig(retail445)
+	for _, tc := range []struct {
+		t                          time.Time
+		day, week, per, qtr, fyear int
+	}{
+		{date(2024, 2, 4), 1, 1, 1, 1, 2024},
+		{date(2024, 3, 2), 28, 4, 1, 1, 2024},
+		{date(2024, 3, 31), 1, 9, 3, 1, 2024},
+		{date(2024, 5, 4), 35, 13, 3, 1, 2024},
+		{date(2024, 5, 5), 1, 14, 4, 2, 2024},
+		// the 53rd week of 2023 is the sixth week of its last period
+		{date(2024, 2, 3), 42, 53, 12, 4, 2023},
+	} {
+		want := []int{tc.day, tc.week, tc.per, tc.qtr, tc.fyear}
+		for i, f := range cc.Fields[2:] {
+			if got := f.GetIndex(tc.t); got != want[i] {
+				t.Errorf("%v %s: expected %d, got %d", tc.t, f.Name, want[i], got)
+			}
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 10, 30, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		u    cronfab.Unit
+		t    time.Time
+		n    int
+		want time.Time
+	}{
+		{PeriodUnit{Calendar: retail445}, at(2024, 2, 10), 1, at(2024, 3, 9)},
+		{PeriodUnit{Calendar: retail445}, at(2024, 4, 30), -1, at(2024, 3, 30)},
+		// from the sixth week of a period to a four week period
+		{PeriodUnit{Calendar: retail445}, at(2024, 1, 30), 1, at(2024, 3, 2)},
+		{QuarterUnit{Calendar: retail445}, at(2024, 2, 4), 4, at(2025, 2, 2)},
+		{YearUnit{Calendar: retail445}, at(2024, 2, 1), -1, at(2023, 1, 28)},
+		{YearUnit{Calendar: retail445}, at(2024, 2, 1), 1, at(2025, 2, 1)},
+	} {
+		if got := tc.u.Add(tc.t, tc.n); !got.Equal(tc.want) {
+			t.Errorf("%s Add(%v, %d): expected %v, got %v", tc.u, tc.t, tc.n, tc.want, got)
+		}
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	cc := NewConfig(retail445)
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 6, 0, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		line  string
+		start time.Time
+		want  []time.Time
+	}{
+		{"0 6 1 * * * *", date(2024, 2, 1), []time.Time{at(2024, 2, 4), at(2024, 3, 3), at(2024, 3, 31), at(2024, 5, 5)}},
+		{"0 6 1 * * * *", date(2023, 12, 20), []time.Time{at(2023, 12, 24), at(2024, 2, 4)}},
+		{"0 6 1 * 1 * *", date(2024, 2, 5), []time.Time{at(2025, 2, 2), at(2026, 2, 1)}},
+		{"0 6 * 53 * * *", date(2024, 1, 1), []time.Time{at(2024, 1, 28), at(2024, 1, 29)}},
+		{"0 6 1 * * 3 2025", date(2024, 1, 1), []time.Time{at(2025, 8, 3), at(2025, 8, 31), at(2025, 9, 28)}},
+	} {
+		ctl, err := cc.ParseCronTab(tc.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := tc.start
+		for i, want := range tc.want {
+			n, err = cc.Next(ctl, n)
+			if err != nil {
+				t.Fatalf("%q: %v", tc.line, err)
+			}
+			if !n.Equal(want) {
+				t.Errorf("%q %d: expected %v, got %v", tc.line, i, want, n)
+			}
+		}
+	}
+}
+
+func TestNRF(t *testing.T) {
+	// the periods of fiscal 2024 start on February 4, March 3, April 7 and May 5
+	for _, tc := range []struct {
+		t      time.Time
+		period int
+	}{
+		{date(2024, 2, 4), 1},
+		{date(2024, 3, 2), 1},
+		{date(2024, 3, 3), 2},
+		{date(2024, 4, 6), 2},
+		{date(2024, 4, 7), 3},
+		{date(2024, 5, 5), 4},
+	} {
+		if got := NRF.index(period, tc.t); got != tc.period {
+			t.Errorf("%v: expected period %d, got %d", tc.t, tc.period, got)
+		}
+	}
+}
+
+func TestSatisfiable(t *testing.T) {
+	cc := NewConfig(NRF)
+	for _, line := range []string{"0 6 1 * * * 2040", "0 6 * 53 * * 2023", "0 6 * * * * 2199"} {
+		ctl, err := cc.ParseCronTab(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cc.Satisfiable(ctl); err != nil {
+			t.Errorf("%q: %v", line, err)
+		}
+	}
+	// 2024 is a 52 week year
+	ctl, err := cc.ParseCronTab("0 6 * 53 * * 2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cc.Satisfiable(ctl); !errors.Is(err, cronfab.ErrUnsatisfiable) {
+		t.Errorf("expected %v, got %v", cronfab.ErrUnsatisfiable, err)
+	}
+}
+
+func TestNewCalendar(t *testing.T) {
+	if _, err := NewCalendar(Pattern{4, 4, 4}, NRF.Start); err != ErrBadPattern {
+		t.Errorf("expected %v, got %v", ErrBadPattern, err)
+	}
+	if _, err := NewCalendar(Pattern454, YearStart{Month: time.February, Day: 29}); err != ErrBadStart {
+		t.Errorf("expected %v, got %v", ErrBadStart, err)
+	}
+	c, err := NewCalendar(Pattern454, YearStart{Month: time.August, Day: 31, Weekday: time.Sunday, Rule: OnOrAfter})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.StartOf(2024, time.UTC), date(2024, 9, 1); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
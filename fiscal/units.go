@@ -0,0 +1,115 @@
+package fiscal
+
+import (
+	"time"
+
+	"github.com/aalpar/cronfab"
+)
+
+// WeekUnit units in fiscal weeks, which start on the weekday of the year start
+type WeekUnit struct {
+	Calendar Calendar
+}
+
+func (WeekUnit) String() string {
+	return "fiscal week"
+}
+
+func (WeekUnit) Add(t time.Time, n int) time.Time {
+	return t.AddDate(0, 0, n*7)
+}
+
+func (WeekUnit) Less(u cronfab.Unit) bool {
+	switch u.(type) {
+	case cronfab.SecondUnit, cronfab.MinuteUnit, cronfab.HourUnit, cronfab.DayUnit, cronfab.BusinessDayUnit,
+		cronfab.WeekOfMonth, cronfab.ISOWeekUnit, WeekUnit:
+		return false
+	}
+	return true
+}
+
+func (u WeekUnit) Trunc(t time.Time) time.Time {
+	return u.Calendar.trunc(week, t)
+}
+
+// PeriodUnit units in fiscal periods, the fiscal months of the pattern
+type PeriodUnit struct {
+	Calendar Calendar
+}
+
+func (PeriodUnit) String() string {
+	return "fiscal period"
+}
+
+// Add keeps the day within the period, or uses the last day of a shorter period
+func (u PeriodUnit) Add(t time.Time, n int) time.Time {
+	return u.Calendar.add(period, t, n)
+}
+
+func (PeriodUnit) Less(u cronfab.Unit) bool {
+	switch u.(type) {
+	case cronfab.SecondUnit, cronfab.MinuteUnit, cronfab.HourUnit, cronfab.DayUnit, cronfab.BusinessDayUnit,
+		cronfab.WeekOfMonth, cronfab.ISOWeekUnit, WeekUnit, cronfab.MonthUnit, PeriodUnit:
+		return false
+	}
+	return true
+}
+
+func (u PeriodUnit) Trunc(t time.Time) time.Time {
+	return u.Calendar.trunc(period, t)
+}
+
+// QuarterUnit units in fiscal quarters
+type QuarterUnit struct {
+	Calendar Calendar
+}
+
+func (QuarterUnit) String() string {
+	return "fiscal quarter"
+}
+
+// Add keeps the day within the quarter, or uses the last day of a shorter quarter
+func (u QuarterUnit) Add(t time.Time, n int) time.Time {
+	return u.Calendar.add(quarter, t, n)
+}
+
+func (QuarterUnit) Less(u cronfab.Unit) bool {
+	switch u.(type) {
+	case cronfab.SecondUnit, cronfab.MinuteUnit, cronfab.HourUnit, cronfab.DayUnit, cronfab.BusinessDayUnit,
+		cronfab.WeekOfMonth, cronfab.ISOWeekUnit, WeekUnit, cronfab.MonthUnit, PeriodUnit, QuarterUnit:
+		return false
+	}
+	return true
+}
+
+func (u QuarterUnit) Trunc(t time.Time) time.Time {
+	return u.Calendar.trunc(quarter, t)
+}
+
+// YearUnit units in fiscal years
+type YearUnit struct {
+	Calendar Calendar
+}
+
+func (YearUnit) String() string {
+	return "fiscal year"
+}
+
+// Add keeps the day within the year, or uses the last day of a 52 week year for the 53rd week
+func (u YearUnit) Add(t time.Time, n int) time.Time {
+	return u.Calendar.add(year, t, n)
+}
+
+func (YearUnit) Less(u cronfab.Unit) bool {
+	switch u.(type) {
+	case cronfab.SecondUnit, cronfab.MinuteUnit, cronfab.HourUnit, cronfab.DayUnit, cronfab.BusinessDayUnit,
+		cronfab.WeekOfMonth, cronfab.ISOWeekUnit, WeekUnit, cronfab.MonthUnit, PeriodUnit, QuarterUnit,
+		cronfab.YearUnit, YearUnit:
+		return false
+	}
+	return true
+}
+
+func (u YearUnit) Trunc(t time.Time) time.Time {
+	return u.Calendar.trunc(year, t)
+}
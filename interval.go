@@ -0,0 +1,117 @@
+package cronfab
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrBadInterval   = errors.New("interval must be positive")
+	ErrIntervalRange = errors.New("next interval time is out of range")
+)
+
+// maxUnix is the latest Unix second a time.Time can hold: its seconds count from year 1
+const maxUnix = math.MaxInt64 - 62135596800
+
+var nanosPerSecond = big.NewInt(int64(time.Second))
+
+// EveryPrefix starts the interval schedules ParseSchedule accepts, like "@every 1h30m"
+const EveryPrefix = "@every "
+
+// IntervalSchedule fires every Every, at Anchor + k·Every for whole k.  Unlike a crontab line it
+// can express intervals that don't divide a field, like 90 seconds.
+type IntervalSchedule struct {
+	Every time.Duration
+	// Anchor is an instant the schedule fires at.  The Unix epoch if zero.
+	Anchor time.Time
+}
+
+// NewIntervalSchedule return a schedule that fires every d, aligned to anchor
+func NewIntervalSchedule(d time.Duration, anchor time.Time) (*IntervalSchedule, error) {
+	if d <= 0 {
+		return nil, ErrBadInterval
+	}
+	return &IntervalSchedule{Every: d, Anchor: anchor}, nil
+}
+
+// Next return the first time after t that is a whole number of intervals from the anchor, in the
+// time zone of t.  If that time is past what time.Time can hold the error is ErrIntervalRange.
+func (is *IntervalSchedule) Next(t time.Time) (time.Time, error) {
+	if is.Every <= 0 {
+		return time.Time{}, ErrBadInterval
+	}
+	anchor := is.anchor()
+	d := t.Sub(anchor)
+	if d > math.MinInt64 && d < math.MaxInt64-is.Every {
+		k := d / is.Every
+		if d < 0 && d%is.Every != 0 {
+			// round toward the past so that anchor + k·Every is at or before t
+			k--
+		}
+		return anchor.Add((k + 1) * is.Every).In(t.Location()), nil
+	}
+	// t is too far from the anchor for a time.Duration
+	every := big.NewInt(int64(is.Every))
+	k := new(big.Int).Div(nanosBetween(anchor, t), every) // rounds toward the past
+	off := k.Mul(k.Add(k, big.NewInt(1)), every)
+	off.Add(off, big.NewInt(int64(anchor.Nanosecond())))
+	sec, nsec := new(big.Int).DivMod(off, nanosPerSecond, new(big.Int))
+	sec.Add(sec, big.NewInt(anchor.Unix()))
+	if !sec.IsInt64() || sec.Int64() > maxUnix {
+		return time.Time{}, ErrIntervalRange
+	}
+	return time.Unix(sec.Int64(), nsec.Int64()).In(t.Location()), nil
+}
+
+// Matches return true if t is a whole number of intervals from the anchor
+func (is *IntervalSchedule) Matches(t time.Time) bool {
+	if is.Every <= 0 {
+		return false
+	}
+	anchor := is.anchor()
+	if d := t.Sub(anchor); d > math.MinInt64 && d < math.MaxInt64 {
+		return d%is.Every == 0
+	}
+	r := new(big.Int).Mod(nanosBetween(anchor, t), big.NewInt(int64(is.Every)))
+	return r.Sign() == 0
+}
+
+// nanosBetween return t - anchor in nanoseconds, which can be more than a time.Duration holds
+func nanosBetween(anchor, t time.Time) *big.Int {
+	d := new(big.Int).Sub(big.NewInt(t.Unix()), big.NewInt(anchor.Unix()))
+	d.Mul(d, nanosPerSecond)
+	return d.Add(d, big.NewInt(int64(t.Nanosecond()-anchor.Nanosecond())))
+}
+
+// anchor return the anchor, or the Unix epoch if it is zero
+func (is *IntervalSchedule) anchor() time.Time {
+	if is.Anchor.IsZero() {
+		return time.Unix(0, 0)
+	}
+	return is.Anchor
+}
+
+func (is *IntervalSchedule) String() string {
+	return EveryPrefix + is.Every.String()
+}
+
+// ParseSchedule parses an interval schedule, "@every " followed by a positive duration in the
+// syntax of time.ParseDuration, or else a crontab line which it compiles.  Interval schedules are
+// anchored at the config's IntervalAnchor.
+func (cc *CrontabConfig) ParseSchedule(s string) (Schedule, error) {
+	if strings.HasPrefix(s, EveryPrefix) {
+		d, err := time.ParseDuration(strings.TrimSpace(s[len(EveryPrefix):]))
+		if err != nil {
+			return nil, err
+		}
+		return NewIntervalSchedule(d, cc.IntervalAnchor)
+	}
+	ctl, err := cc.ParseCronTab(s)
+	if err != nil {
+		return nil, err
+	}
+	return cc.Compile(ctl)
+}
@@ -0,0 +1,134 @@
+package cronfab
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIntervalSchedule_Next(t *testing.T) {
+	anchor := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		every time.Duration
+		t     time.Time
+		want  time.Time
+	}{
+		{90 * time.Second, anchor, anchor.Add(90 * time.Second)},
+		{90 * time.Second, anchor.Add(time.Minute), anchor.Add(90 * time.Second)},
+		{90 * time.Second, anchor.Add(90 * time.Second), anchor.Add(180 * time.Second)},
+		{90 * time.Second, anchor.Add(-time.Second), anchor},
+		{90 * time.Second, anchor.Add(-90 * time.Second), anchor},
+		{90 * time.Second, anchor.Add(-91 * time.Second), anchor.Add(-90 * time.Second)},
+		{7 * time.Hour, anchor.Add(24 * time.Hour), anchor.Add(28 * time.Hour)},
+	} {
+		s, err := NewIntervalSchedule(tc.every, anchor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.Next(tc.t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("every %v after %v: expected %v, got %v", tc.every, tc.t, tc.want, got)
+		}
+	}
+}
+
+func TestIntervalSchedule_Epoch(t *testing.T) {
+	s := &IntervalSchedule{Every: time.Hour}
+	loc := time.FixedZone("UTC+0530", 5*3600+1800)
+	got, err := s.Next(time.Date(2024, 3, 1, 10, 10, 0, 0, loc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// hours from the epoch fall on the half hour in this zone
+	if want := time.Date(2024, 3, 1, 10, 30, 0, 0, loc); !got.Equal(want) || got.Location() != loc {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if _, err := (&IntervalSchedule{}).Next(got); !errors.Is(err, ErrBadInterval) {
+		t.Errorf("expected %v, got %v", ErrBadInterval, err)
+	}
+}
+
+func TestIntervalSchedule_Far(t *testing.T) {
+	for _, tc := range []struct {
+		every time.Duration
+		t     time.Time
+		want  time.Time
+	}{
+		{time.Hour, time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2300, 1, 1, 1, 0, 0, 0, time.UTC)},
+		{time.Hour, time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(1, 1, 1, 1, 0, 0, 0, time.UTC)},
+		{time.Hour, time.Date(1, 1, 1, 0, 59, 59, 999999999, time.UTC), time.Date(1, 1, 1, 1, 0, 0, 0, time.UTC)},
+		// 2300-01-01 is 10,227,456,000 seconds from the epoch, 90 seconds times 113,638,400
+		{90 * time.Second, time.Date(2300, 1, 1, 0, 0, 1, 0, time.UTC), time.Date(2300, 1, 1, 0, 1, 30, 0, time.UTC)},
+		// 0001-01-01 is 62,135,596,800 seconds before the epoch, 90 seconds times 690,395,520
+		{90 * time.Second, time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(1, 1, 1, 0, 1, 30, 0, time.UTC)},
+		{90 * time.Second, time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond), time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		s := &IntervalSchedule{Every: tc.every}
+		got, err := s.Next(tc.t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("every %v after %v: expected %v, got %v", tc.every, tc.t, tc.want, got)
+		}
+		if !s.Matches(tc.want) || s.Matches(tc.want.Add(time.Second)) {
+			t.Errorf("every %v: unexpected Matches around %v", tc.every, tc.want)
+		}
+	}
+
+	// an anchor with nanoseconds far from t
+	anchor := time.Date(2024, 1, 1, 0, 0, 0, 500, time.UTC)
+	s := &IntervalSchedule{Every: time.Second, Anchor: anchor}
+	if got, err := s.Next(time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil || !got.Equal(time.Date(3000, 1, 1, 0, 0, 0, 500, time.UTC)) {
+		t.Errorf("unexpected %v, %v", got, err)
+	}
+
+	// the last time time.Time can hold has no next
+	last := time.Unix(maxUnix, 999999999)
+	if _, err := (&IntervalSchedule{Every: time.Hour}).Next(last); !errors.Is(err, ErrIntervalRange) {
+		t.Errorf("expected %v, got %v", ErrIntervalRange, err)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	cc := MustCrontabConfig(DefaultCrontabConfig.Fields)
+	cc.IntervalAnchor = time.Date(2024, 1, 1, 0, 0, 20, 0, time.UTC)
+	s, err := cc.ParseSchedule("@every 1h30m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	is, ok := s.(*IntervalSchedule)
+	if !ok || is.Every != 90*time.Minute || !is.Anchor.Equal(cc.IntervalAnchor) {
+		t.Fatalf("unexpected schedule %#v", s)
+	}
+	if is.String() != "@every 1h30m0s" {
+		t.Errorf("unexpected %q", is.String())
+	}
+	got, err := s.Next(time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 1, 1, 3, 0, 20, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	s, err = cc.ParseSchedule("*/5 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.(*CompiledLine); !ok {
+		t.Errorf("expected a compiled line, got %T", s)
+	}
+
+	for _, bad := range []string{"@every", "@every 0s", "@every -1m", "@every 5 minutes", "61 * * * *"} {
+		if _, err := cc.ParseSchedule(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+	if _, err := cc.ParseSchedule("@every 0s"); !errors.Is(err, ErrBadInterval) {
+		t.Errorf("expected %v, got %v", ErrBadInterval, err)
+	}
+}
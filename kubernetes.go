@@ -0,0 +1,281 @@
+package cronfab
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrEmptySpec         = errors.New("empty spec")
+	ErrUnknownDescriptor = errors.New("unrecognized descriptor")
+)
+
+// KubernetesCrontabConfig is the config of Kubernetes CronJob schedules: the five fields of
+// DefaultCrontabConfig with the three letter month and weekday names of the Kubernetes
+// controller.  Use ParseKubernetesSchedule to accept exactly the schedules the controller
+// accepts; ParseCronTab with this config follows the cronfab syntax.
+var KubernetesCrontabConfig = MustCrontabConfig([]FieldConfig{
+	{
+		Unit: MinuteUnit{},
+		Name: "minute",
+		Min:  0,
+		Max:  59,
+		GetIndex: func(t time.Time) int {
+			return t.Minute()
+		},
+	},
+	{
+		Unit: HourUnit{},
+		Name: "hour",
+		Min:  0,
+		Max:  23,
+		GetIndex: func(t time.Time) int {
+			return t.Hour()
+		},
+	},
+	{
+		Unit: DayUnit{},
+		Name: "day of month",
+		Min:  1,
+		Max:  31,
+		GetIndex: func(t time.Time) int {
+			return t.Day()
+		},
+	},
+	{
+		Unit:       MonthUnit{},
+		Name:       "month",
+		RangeNames: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"},
+		Min:        1,
+		Max:        12,
+		GetIndex: func(t time.Time) int {
+			return int(t.Month())
+		},
+	},
+	{
+		Unit:       DayUnit{},
+		Name:       "day of week",
+		RangeNames: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"},
+		Min:        0,
+		Max:        6,
+		GetIndex: func(t time.Time) int {
+			return int(t.Weekday())
+		},
+	},
+})
+
+func init() {
+	KubernetesCrontabConfig.Name = "kubernetes"
+	KubernetesCrontabConfig.Aliases = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+}
+
+// indexes of the day fields of KubernetesCrontabConfig
+const (
+	kubernetesDayOfMonth = 2
+	kubernetesDayOfWeek  = 4
+)
+
+// KubernetesSchedule is a parsed Kubernetes CronJob schedule
+type KubernetesSchedule struct {
+	// Location is the time zone of a CRON_TZ= or TZ= prefix, or nil to use the time zone of the
+	// time passed to Next
+	Location *time.Location
+	// Every is the interval of an "@every" schedule, zero otherwise
+	Every time.Duration
+	// Lines are the lines of KubernetesCrontabConfig the schedule fires on.  There are two when
+	// both day of month and day of week are restricted, because either one may match.
+	Lines []*CompiledLine
+}
+
+// ParseKubernetesSchedule parses a CronJob schedule the way the Kubernetes controller does: an
+// optional CRON_TZ= or TZ= prefix, then "@every <duration>", a descriptor like "@daily", or exactly
+// five fields.  Fields take numbers, the three letter names of KubernetesCrontabConfig, "*" or
+// "?", ranges "a-b", steps "/n" where "a/n" runs from a to the field's maximum, and lists.  Unlike
+// the other configs, when neither day of month nor day of week is "*" or "?" either may match.
+func ParseKubernetesSchedule(spec string) (*KubernetesSchedule, error) {
+	if spec == "" {
+		return nil, ErrEmptySpec
+	}
+	ks := &KubernetesSchedule{}
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		i := strings.IndexByte(spec, ' ')
+		if i < 0 {
+			return nil, ErrFieldCount
+		}
+		loc, err := time.LoadLocation(spec[strings.IndexByte(spec, '=')+1 : i])
+		if err != nil {
+			return nil, err
+		}
+		ks.Location = loc
+		spec = strings.TrimSpace(spec[i:])
+	}
+	if strings.HasPrefix(spec, "@") {
+		return ks, ks.parseDescriptor(spec)
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != len(KubernetesCrontabConfig.Fields) {
+		return nil, ErrFieldCount
+	}
+	ctl := make(CrontabLine, len(fields))
+	star := make([]bool, len(fields))
+	for i, s := range fields {
+		var err error
+		ctl[i], star[i], err = parseKubernetesField(KubernetesCrontabConfig.Fields[i], s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if star[kubernetesDayOfMonth] || star[kubernetesDayOfWeek] {
+		return ks, ks.add(ctl)
+	}
+	// either day field may match: fire on the days of month of any weekday, and on the weekdays
+	// of any day of month
+	byWeekday := make(CrontabLine, len(ctl))
+	copy(byWeekday, ctl)
+	ctl[kubernetesDayOfWeek] = CrontabField{{0, 6, 1}}
+	byWeekday[kubernetesDayOfMonth] = CrontabField{{1, 31, 1}}
+	if err := ks.add(ctl); err != nil {
+		return nil, err
+	}
+	return ks, ks.add(byWeekday)
+}
+
+// parseDescriptor parses an "@every" schedule or one of the aliases of KubernetesCrontabConfig
+func (ks *KubernetesSchedule) parseDescriptor(spec string) error {
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(spec[len("@every "):])
+		if err != nil {
+			return err
+		}
+		// the controller rounds down to whole seconds, with a floor of one second
+		if d < time.Second {
+			d = time.Second
+		}
+		ks.Every = d - d%time.Second
+		return nil
+	}
+	expr, ok := KubernetesCrontabConfig.Aliases[spec]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownDescriptor, spec)
+	}
+	ctl, err := KubernetesCrontabConfig.ParseCronTab(expr)
+	if err != nil {
+		return err
+	}
+	return ks.add(ctl)
+}
+
+// add compiles a line of KubernetesCrontabConfig into the schedule
+func (ks *KubernetesSchedule) add(ctl CrontabLine) error {
+	cl, err := KubernetesCrontabConfig.Compile(ctl)
+	if err != nil {
+		return err
+	}
+	ks.Lines = append(ks.Lines, cl)
+	return nil
+}
+
+// parseKubernetesField parses one field of a schedule.  star is true if the field has a "*" or "?"
+// without a step greater than one.
+func parseKubernetesField(f FieldConfig, s string) (CrontabField, bool, error) {
+	var cf CrontabField
+	star := false
+	for _, part := range strings.Split(s, ",") {
+		rangeAndStep := strings.Split(part, "/")
+		lowAndHigh := strings.Split(rangeAndStep[0], "-")
+		all := lowAndHigh[0] == "*" || lowAndHigh[0] == "?"
+		// the controller ignores anything after the hyphen of "*-"
+		if len(rangeAndStep) > 2 || (len(lowAndHigh) > 2 && !all) {
+			return nil, false, &ErrorBadName{FieldName: f.Name, Value: part}
+		}
+		c := CrontabConstraint{f.Min, f.Max, 1}
+		if !all {
+			var err error
+			c[0], err = parseKubernetesValue(f, lowAndHigh[0])
+			if err != nil {
+				return nil, false, err
+			}
+			c[1] = c[0]
+			if len(lowAndHigh) == 2 {
+				c[1], err = parseKubernetesValue(f, lowAndHigh[1])
+				if err != nil {
+					return nil, false, err
+				}
+			}
+		}
+		if len(rangeAndStep) == 2 {
+			step, err := strconv.Atoi(rangeAndStep[1])
+			if err != nil || step < 1 {
+				return nil, false, &ErrorBadName{FieldName: f.Name, Value: part}
+			}
+			c[2] = step
+			if len(lowAndHigh) == 1 {
+				// "a/n" runs from a to the maximum
+				c[1] = f.Max
+			}
+		}
+		if c[0] > c[1] {
+			return nil, false, ErrConstraintBoundariesReversed
+		}
+		if all && c[2] == 1 {
+			star = true
+		}
+		cf = append(cf, c)
+	}
+	return cf, star, nil
+}
+
+// parseKubernetesValue parses a number or name in the range of f
+func parseKubernetesValue(f FieldConfig, s string) (int, error) {
+	for i, name := range f.RangeNames {
+		if strings.EqualFold(s, name) {
+			return f.Min + i, nil
+		}
+	}
+	k, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, &ErrorBadName{FieldName: f.Name, Value: s}
+	}
+	if k < f.Min || k > f.Max {
+		return 0, &ErrorBadIndex{FieldName: f.Name, Value: k}
+	}
+	return k, nil
+}
+
+// Next return the next time after t that the schedule fires, in the time zone of t
+func (ks *KubernetesSchedule) Next(t time.Time) (time.Time, error) {
+	if ks.Every > 0 {
+		return t.Add(ks.Every - time.Duration(t.Nanosecond())), nil
+	}
+	loc := t.Location()
+	if ks.Location != nil {
+		t = t.In(ks.Location)
+	}
+	var q time.Time
+	var qerr error
+	for _, cl := range ks.Lines {
+		n, err := cl.Next(t)
+		if err != nil {
+			qerr = err
+			continue
+		}
+		if q.IsZero() || n.Before(q) {
+			q = n
+		}
+	}
+	if q.IsZero() {
+		return q, qerr
+	}
+	return q.In(loc), nil
+}
@@ -0,0 +1,149 @@
+package cronfab
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseKubernetesSchedule(t *testing.T) {
+	// schedules from the Kubernetes CronJob documentation, and corner cases of its parser
+	for _, tc := range []struct {
+		spec string
+		ok   bool
+	}{
+		{"*/1 * * * *", true},
+		{"0 0 13 * 5", true},
+		{"0 3 * * 1", true},
+		{"@yearly", true},
+		{"@annually", true},
+		{"@monthly", true},
+		{"@weekly", true},
+		{"@daily", true},
+		{"@midnight", true},
+		{"@hourly", true},
+		{"@every 1h30m", true},
+		{"@every 500ms", true},
+		{"CRON_TZ=UTC 0 23 * * *", true},
+		{"TZ=America/New_York @daily", true},
+		{"0 0 ? * MON", true},
+		{"5/15 * * * *", true},
+		{"0 9-17 * * mon-fri", true},
+		{"0 0 1,15 jan-jun/2 *", true},
+		{"*-5 * * * *", true},
+		{" 0 0 * * * ", true},
+		{"* * * * * *", false},
+		{"* * * *", false},
+		{"", false},
+		{"60 * * * *", false},
+		{"* 24 * * *", false},
+		{"* * 0 * *", false},
+		{"* * * 13 *", false},
+		{"* * * * 7", false},
+		{"* * * january *", false},
+		{"* * * * mond", false},
+		{"* * * * mon-", false},
+		{"*/0 * * * *", false},
+		{"5-1 * * * *", false},
+		{"1/2/3 * * * *", false},
+		{"1-2-3 * * * *", false},
+		{"1,,2 * * * *", false},
+		{"@fortnightly", false},
+		{"@DAILY", false},
+		{"@every 1x", false},
+		{" @daily", false},
+		{"TZ=Nowhere/Special * * * * *", false},
+		{"CRON_TZ=UTC", false},
+	} {
+		_, err := ParseKubernetesSchedule(tc.spec)
+		if tc.ok && err != nil {
+			t.Errorf("%q: unexpected error %v", tc.spec, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("%q: expected an error", tc.spec)
+		}
+	}
+	if _, err := ParseKubernetesSchedule("@fortnightly"); !errors.Is(err, ErrUnknownDescriptor) {
+		t.Errorf("expected %v, got %v", ErrUnknownDescriptor, err)
+	}
+	if _, err := ParseKubernetesSchedule("* * * *"); !errors.Is(err, ErrFieldCount) {
+		t.Errorf("expected %v, got %v", ErrFieldCount, err)
+	}
+}
+
+func TestKubernetesSchedule_Next(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) // a Monday
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		spec string
+		want []time.Time
+	}{
+		// Fridays and the 13th
+		{"0 0 13 * 5", []time.Time{day(5), day(12), day(13), day(19), day(26)}},
+		{"0 0 13 * *", []time.Time{day(13), time.Date(2024, 2, 13, 0, 0, 0, 0, time.UTC)}},
+		{"0 0 ? * fri", []time.Time{day(5), day(12), day(19)}},
+		{"0 0 13 * ?", []time.Time{day(13)}},
+		// a step clears the wildcard, so odd days or Mondays
+		{"0 0 */2 * 1", []time.Time{day(3), day(5), day(7), day(8), day(9)}},
+		{"0 0 13 * */1", []time.Time{day(13)}},
+		{"5/15 0 * * *", []time.Time{
+			time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 0, 20, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 0, 35, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 0, 50, 0, 0, time.UTC),
+			time.Date(2024, 1, 2, 0, 5, 0, 0, time.UTC),
+		}},
+		{"@weekly", []time.Time{day(7), day(14)}},
+		{"CRON_TZ=America/New_York 0 9 * * *", []time.Time{
+			time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC),
+		}},
+	} {
+		ks, err := ParseKubernetesSchedule(tc.spec)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.spec, err)
+		}
+		n := start
+		for _, want := range tc.want {
+			n, err = ks.Next(n)
+			if err != nil {
+				t.Fatalf("%q: %v", tc.spec, err)
+			}
+			if !n.Equal(want) || n.Location() != time.UTC {
+				t.Errorf("%q: expected %v, got %v", tc.spec, want, n)
+				break
+			}
+		}
+	}
+}
+
+func TestKubernetesSchedule_Every(t *testing.T) {
+	for _, tc := range []struct {
+		spec  string
+		every time.Duration
+	}{
+		{"@every 1h30m", 90 * time.Minute},
+		{"@every 1500ms", time.Second},
+		{"@every 10ms", time.Second},
+	} {
+		ks, err := ParseKubernetesSchedule(tc.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ks.Every != tc.every {
+			t.Errorf("%q: expected %v, got %v", tc.spec, tc.every, ks.Every)
+		}
+		t0 := time.Date(2024, 1, 1, 0, 0, 0, 250, time.UTC)
+		n, err := ks.Next(t0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(tc.every); !n.Equal(want) {
+			t.Errorf("%q: expected %v, got %v", tc.spec, want, n)
+		}
+	}
+}
+
+var _ Schedule = (*KubernetesSchedule)(nil)
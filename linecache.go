@@ -0,0 +1,65 @@
+package cronfab
+
+import (
+	"sync"
+)
+
+// lineCacheSize is how many compiled lines a config keeps for Next
+const lineCacheSize = 16
+
+// lineCache keeps the lines a config compiled last, so that the Next of a line that is searched
+// again and again does not compile it on every call.  The oldest line makes way for a new one.
+type lineCache struct {
+	mu    sync.Mutex
+	lines [lineCacheSize]*CompiledLine
+	next  int
+}
+
+// get return the compiled line for ctl, or nil if it is not in the cache.  Only lines compiled
+// against cc match, so that a config copied by value does not search with the sets of the other.
+func (lc *lineCache) get(cc *CrontabConfig, ctl CrontabLine) *CompiledLine {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	for _, cl := range lc.lines {
+		if cl != nil && cl.cc == cc && sameLine(cl.line, ctl) {
+			return cl
+		}
+	}
+	return nil
+}
+
+// put adds the compiled line to the cache
+func (lc *lineCache) put(cl *CompiledLine) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.lines[lc.next] = cl
+	lc.next = (lc.next + 1) % lineCacheSize
+}
+
+// copyLine return a copy of the line that shares nothing with it, so that the caller can change
+// its line after it is cached
+func copyLine(ctl CrontabLine) CrontabLine {
+	q := make(CrontabLine, len(ctl))
+	for i, cf := range ctl {
+		q[i] = append([][3]int(nil), cf...)
+	}
+	return q
+}
+
+// sameLine return true if a and b have the same constraints in the same order
+func sameLine(a, b CrontabLine) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false
+		}
+		for j := range a[i] {
+			if a[i][j] != b[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+// Package metrics publishes cronfab search costs and runner scheduling lag through expvar, so
+// they can be read from /debug/vars without any other dependency.
+package metrics
+
+import (
+	"expvar"
+	"sync"
+
+	"github.com/aalpar/cronfab"
+	"github.com/aalpar/cronfab/runner"
+)
+
+// DefaultMaxExpressions is the number of expressions a SearchObserver counts separately when its
+// MaxExpressions is zero
+const DefaultMaxExpressions = 100
+
+// OtherExpressions is the "by_expression" key that counts the searches of expressions past the
+// limit
+const OtherExpressions = "other"
+
+// SearchObserver is a cronfab.SearchObserver that counts searches, errors, iterations, elapsed
+// nanoseconds and rolls per unit, in total and for each expression under "by_expression".  Only
+// the first MaxExpressions expressions get counters of their own, so that a config searched with
+// many different lines does not grow without bound; the rest are counted together under
+// OtherExpressions.
+type SearchObserver struct {
+	// MaxExpressions is the number of expressions counted separately.  DefaultMaxExpressions if
+	// zero; no expression is if negative.  Set it before the observer is used.
+	MaxExpressions int
+	units          []string
+	m              *expvar.Map
+	mu             sync.Mutex
+	byExpr         *expvar.Map
+	nExpr          int
+	rolls          *expvar.Map
+}
+
+// NewSearchObserver return an observer for searches of cc that publishes into m
+func NewSearchObserver(cc *cronfab.CrontabConfig, m *expvar.Map) *SearchObserver {
+	o := &SearchObserver{
+		m:      m,
+		byExpr: new(expvar.Map).Init(),
+		rolls:  new(expvar.Map).Init(),
+	}
+	for _, u := range cc.Units {
+		o.units = append(o.units, u.String())
+	}
+	m.Set("by_expression", o.byExpr)
+	m.Set("rolls", o.rolls)
+	return o
+}
+
+// Observe sets the observer of cc to a SearchObserver published by expvar under name.  Like
+// expvar.NewMap, it panics if name is already published.
+func Observe(cc *cronfab.CrontabConfig, name string) *SearchObserver {
+	o := NewSearchObserver(cc, expvar.NewMap(name))
+	cc.Observer = o
+	return o
+}
+
+func (o *SearchObserver) ObserveSearch(ctl cronfab.CrontabLine, st cronfab.SearchStats, err error) {
+	add(o.m, st, err)
+	add(o.expression(ctl.String()), st, err)
+	for k, q := range st.Rolls {
+		if q > 0 && k < len(o.units) {
+			o.rolls.Add(o.units[k], int64(q))
+		}
+	}
+}
+
+// expression return the map of counters for expr, or for OtherExpressions once MaxExpressions
+// expressions have counters
+func (o *SearchObserver) expression(expr string) *expvar.Map {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if m, ok := o.byExpr.Get(expr).(*expvar.Map); ok {
+		return m
+	}
+	max := o.MaxExpressions
+	if max == 0 {
+		max = DefaultMaxExpressions
+	}
+	if o.nExpr >= max {
+		expr = OtherExpressions
+		if m, ok := o.byExpr.Get(expr).(*expvar.Map); ok {
+			return m
+		}
+	} else {
+		o.nExpr++
+	}
+	m := new(expvar.Map).Init()
+	o.byExpr.Set(expr, m)
+	return m
+}
+
+func add(m *expvar.Map, st cronfab.SearchStats, err error) {
+	m.Add("searches", 1)
+	m.Add("iterations", int64(st.Iterations))
+	m.Add("elapsed_ns", int64(st.Elapsed))
+	if err != nil {
+		m.Add("errors", 1)
+	}
+}
+
+// RunnerHooks are runner.Hooks that count each job's events and publish its scheduling lag: the
+// lag of the last start, the total lag of all starts and the largest lag, in nanoseconds.  Only
+// the first attempt of a run counts towards the lag; retries are counted under "retries".
+type RunnerHooks struct {
+	m    *expvar.Map
+	mu   sync.Mutex
+	jobs map[string]*jobVars
+}
+
+type jobVars struct {
+	m        *expvar.Map
+	lag      *expvar.Int
+	lagTotal *expvar.Int
+	lagMax   *expvar.Int
+}
+
+// NewRunnerHooks return hooks that publish a map of counters per job into m
+func NewRunnerHooks(m *expvar.Map) *RunnerHooks {
+	return &RunnerHooks{m: m, jobs: map[string]*jobVars{}}
+}
+
+// job return the variables of the named job
+func (h *RunnerHooks) job(name string) *jobVars {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if v, ok := h.jobs[name]; ok {
+		return v
+	}
+	v := &jobVars{
+		m:        new(expvar.Map).Init(),
+		lag:      new(expvar.Int),
+		lagTotal: new(expvar.Int),
+		lagMax:   new(expvar.Int),
+	}
+	v.m.Set("lag_ns", v.lag)
+	v.m.Set("lag_total_ns", v.lagTotal)
+	v.m.Set("lag_max_ns", v.lagMax)
+	h.m.Set(name, v.m)
+	h.jobs[name] = v
+	return v
+}
+
+func (h *RunnerHooks) OnScheduled(e runner.Event) {
+	h.job(e.Name).m.Add("scheduled", 1)
+}
+
+func (h *RunnerHooks) OnStart(e runner.Event) {
+	v := h.job(e.Name)
+	v.m.Add("starts", 1)
+	if e.Attempt > 1 {
+		// a retry starts late by its backoff, which is not scheduling lag
+		v.m.Add("retries", 1)
+		return
+	}
+	lag := int64(e.Lag())
+	v.lag.Set(lag)
+	v.lagTotal.Add(lag)
+	h.mu.Lock()
+	if lag > v.lagMax.Value() {
+		v.lagMax.Set(lag)
+	}
+	h.mu.Unlock()
+}
+
+func (h *RunnerHooks) OnFinish(e runner.Event) {
+	v := h.job(e.Name)
+	v.m.Add("finishes", 1)
+	if e.Err != nil {
+		v.m.Add("failures", 1)
+	}
+}
+
+func (h *RunnerHooks) OnSkip(e runner.Event) {
+	h.job(e.Name).m.Add("skips", 1)
+}
+
+func (h *RunnerHooks) OnMisfire(e runner.Event) {
+	h.job(e.Name).m.Add("misfires", 1)
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/aalpar/cronfab"
+	"github.com/aalpar/cronfab/runner"
+)
+
+func intValue(t *testing.T, m *expvar.Map, key string) int64 {
+	t.Helper()
+	v, ok := m.Get(key).(*expvar.Int)
+	if !ok {
+		t.Fatalf("no counter %q in %v", key, m)
+	}
+	return v.Value()
+}
+
+func TestSearchObserver(t *testing.T) {
+	cc := cronfab.MustCrontabConfig(cronfab.DefaultCrontabConfig.Fields)
+	m := new(expvar.Map).Init()
+	cc.Observer = NewSearchObserver(cc, m)
+
+	ctl, err := cc.ParseCronTab("30 4 1 * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	_, st, err := cc.NextWithStats(ctl, start, cronfab.NextOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cc.Next(ctl, start); err != nil {
+		t.Fatal(err)
+	}
+	bad, err := cc.ParseCronTab("0 0 30 feb *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cc.NextWithOptions(bad, start, cronfab.NextOptions{MaxIt: 10}); err == nil {
+		t.Fatal("expected a search limit error")
+	}
+
+	if got := intValue(t, m, "searches"); got != 3 {
+		t.Errorf("expected 3 searches, got %d", got)
+	}
+	if got := intValue(t, m, "errors"); got != 1 {
+		t.Errorf("expected 1 error, got %d", got)
+	}
+	byExpr := m.Get("by_expression").(*expvar.Map)
+	em, ok := byExpr.Get(ctl.String()).(*expvar.Map)
+	if !ok {
+		t.Fatalf("no counters for %q in %v", ctl.String(), byExpr)
+	}
+	if got := intValue(t, em, "iterations"); got != int64(2*st.Iterations) {
+		t.Errorf("expected %d iterations, got %d", 2*st.Iterations, got)
+	}
+	rolls := m.Get("rolls").(*expvar.Map)
+	if got := intValue(t, rolls, "day"); got == 0 {
+		t.Errorf("expected day rolls in %v", rolls)
+	}
+}
+
+func TestSearchObserver_MaxExpressions(t *testing.T) {
+	cc := cronfab.MustCrontabConfig(cronfab.DefaultCrontabConfig.Fields)
+	m := new(expvar.Map).Init()
+	o := NewSearchObserver(cc, m)
+	o.MaxExpressions = 2
+	cc.Observer = o
+	start := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	var exprs []string
+	for _, line := range []string{"0 * * * *", "1 * * * *", "2 * * * *", "3 * * * *", "0 * * * *"} {
+		ctl, err := cc.ParseCronTab(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cc.Next(ctl, start); err != nil {
+			t.Fatal(err)
+		}
+		exprs = append(exprs, ctl.String())
+	}
+	byExpr := m.Get("by_expression").(*expvar.Map)
+	n := 0
+	byExpr.Do(func(expvar.KeyValue) { n++ })
+	if n != 3 {
+		t.Errorf("expected 2 expressions and %q, got %v", OtherExpressions, byExpr)
+	}
+	for key, want := range map[string]int64{exprs[0]: 2, exprs[1]: 1, OtherExpressions: 2} {
+		em, ok := byExpr.Get(key).(*expvar.Map)
+		if !ok {
+			t.Fatalf("no counters for %q in %v", key, byExpr)
+		}
+		if got := intValue(t, em, "searches"); got != want {
+			t.Errorf("%q: expected %d searches, got %d", key, want, got)
+		}
+	}
+}
+
+func TestRunnerHooks(t *testing.T) {
+	m := new(expvar.Map).Init()
+	h := NewRunnerHooks(m)
+	t0 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	h.OnScheduled(runner.Event{Name: "job", Scheduled: t0, Actual: t0.Add(-time.Minute)})
+	h.OnStart(runner.Event{Name: "job", Scheduled: t0, Actual: t0.Add(3 * time.Second), Attempt: 1})
+	h.OnFinish(runner.Event{Name: "job", Scheduled: t0, Actual: t0.Add(5 * time.Second), Attempt: 1, Err: errors.New("boom")})
+	// the retry's backoff is not lag
+	h.OnStart(runner.Event{Name: "job", Scheduled: t0, Actual: t0.Add(time.Minute), Attempt: 2})
+	t1 := t0.Add(time.Hour)
+	h.OnStart(runner.Event{Name: "job", Scheduled: t1, Actual: t1.Add(time.Second), Attempt: 1})
+	h.OnSkip(runner.Event{Name: "job"})
+	h.OnMisfire(runner.Event{Name: "job"})
+
+	jm, ok := m.Get("job").(*expvar.Map)
+	if !ok {
+		t.Fatalf("no counters for job in %v", m)
+	}
+	for key, want := range map[string]int64{
+		"scheduled":    1,
+		"starts":       3,
+		"retries":      1,
+		"finishes":     1,
+		"failures":     1,
+		"skips":        1,
+		"misfires":     1,
+		"lag_ns":       int64(time.Second),
+		"lag_total_ns": int64(4 * time.Second),
+		"lag_max_ns":   int64(3 * time.Second),
+	} {
+		if got := intValue(t, jm, key); got != want {
+			t.Errorf("%s: expected %d, got %d", key, want, got)
+		}
+	}
+}
+
+var _ runner.Hooks = (*RunnerHooks)(nil)
@@ -1,7 +1,6 @@
 package cronfab
 
 import (
-	"fmt"
 	"unicode"
 	"unicode/utf8"
 )
@@ -49,17 +48,38 @@ func StateString(x State) string {
 }
 
 // ParseCronTab parses a crontab string using the crontab configuration.
-// If the string starts with '@', it is looked up in the config's Aliases map.
+// If the string starts with '@', it is looked up in the config's Aliases map, with the
+// arguments of a call like "@at(14:30)" substituted.  See RegisterAlias.
+// If the config is Strict, the line must also be Satisfiable.
 func (cc *CrontabConfig) ParseCronTab(s string) (CrontabLine, error) {
+	markers, err := cc.parseCronTab(s)
+	if err != nil {
+		return CrontabLine{}, err
+	}
+	if cc.Strict {
+		err = cc.Satisfiable(markers)
+		if err != nil {
+			return CrontabLine{}, err
+		}
+	}
+	return markers, nil
+}
+
+// parseCronTab parses a crontab string without the strict checks
+func (cc *CrontabConfig) parseCronTab(s string) (CrontabLine, error) {
 	if len(s) == 0 {
 		return CrontabLine{}, nil
 	}
 	if s[0] == '@' && cc.Aliases != nil {
-		expr, ok := cc.Aliases[s]
-		if !ok {
-			return CrontabLine{}, fmt.Errorf("unknown alias %q", s)
+		name, args, err := splitAliasCall(s)
+		if err != nil {
+			return CrontabLine{}, &ErrorAlias{Name: s, Err: err}
+		}
+		expr, err := cc.expandAlias(name, args, map[string]bool{})
+		if err != nil {
+			return CrontabLine{}, err
 		}
-		return cc.ParseCronTab(expr)
+		return cc.parseCronTab(expr)
 	}
 	i := 0
 	j := 0
@@ -0,0 +1,180 @@
+package runner
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMisfireThreshold is how late a firing may be before it is a misfire, when
+// Options.MisfireThreshold is zero
+const DefaultMisfireThreshold = time.Second
+
+// EventType is the kind of an Event
+type EventType int
+
+const (
+	// EventScheduled: the job is waiting for its next fire time, Scheduled
+	EventScheduled EventType = iota
+	// EventStart: an attempt of a run started
+	EventStart
+	// EventFinish: an attempt of a run returned.  Err is set if it failed.  The event of a run's
+	// last attempt is sent once the job's Stats count the finished run.
+	EventFinish
+	// EventSkip: a firing was not run.  Reason says why.
+	EventSkip
+	// EventMisfire: a firing was reached more than the misfire threshold after its fire time.
+	// The firing still goes ahead.
+	EventMisfire
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventScheduled:
+		return "scheduled"
+	case EventStart:
+		return "start"
+	case EventFinish:
+		return "finish"
+	case EventSkip:
+		return "skip"
+	case EventMisfire:
+		return "misfire"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Reasons for EventSkip
+const (
+	SkipOverlap     = "overlap"
+	SkipQueueFull   = "queue full"
+	SkipNotAcquired = "not acquired"
+	SkipLockError   = "lock error"
+)
+
+// Event is something that happened to a job
+type Event struct {
+	Type EventType
+	// Name is the job name
+	Name string
+	// Scheduled is the fire time the event is about
+	Scheduled time.Time
+	// Actual is the clock time the event happened
+	Actual time.Time
+	// Attempt is the attempt number for EventStart and EventFinish
+	Attempt int
+	// Err is the error of a failed attempt, or of the lock for SkipLockError
+	Err error
+	// Reason says why a firing was skipped
+	Reason string
+}
+
+// Lag return how long after the fire time the event happened
+func (e Event) Lag() time.Duration {
+	return e.Actual.Sub(e.Scheduled)
+}
+
+func (e Event) String() string {
+	s := fmt.Sprintf("%s %s scheduled %s lag %v", e.Name, e.Type, e.Scheduled.Format(time.RFC3339), e.Lag())
+	if e.Attempt > 0 {
+		s += fmt.Sprintf(" attempt %d", e.Attempt)
+	}
+	if e.Reason != "" {
+		s += " (" + e.Reason + ")"
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// Hooks are called as jobs move through their lifecycle.  They are called synchronously from the
+// runner's goroutines, so they must be quick and safe for concurrent use.
+type Hooks interface {
+	OnScheduled(Event)
+	OnStart(Event)
+	OnFinish(Event)
+	OnSkip(Event)
+	OnMisfire(Event)
+}
+
+// NopHooks does nothing.  Embed it to implement only some of the hooks.
+type NopHooks struct{}
+
+func (NopHooks) OnScheduled(Event) {}
+func (NopHooks) OnStart(Event)     {}
+func (NopHooks) OnFinish(Event)    {}
+func (NopHooks) OnSkip(Event)      {}
+func (NopHooks) OnMisfire(Event)   {}
+
+// MultiHooks return hooks that call each of hooks in turn
+func MultiHooks(hooks ...Hooks) Hooks {
+	return multiHooks(hooks)
+}
+
+type multiHooks []Hooks
+
+func (m multiHooks) OnScheduled(e Event) {
+	for _, h := range m {
+		h.OnScheduled(e)
+	}
+}
+
+func (m multiHooks) OnStart(e Event) {
+	for _, h := range m {
+		h.OnStart(e)
+	}
+}
+
+func (m multiHooks) OnFinish(e Event) {
+	for _, h := range m {
+		h.OnFinish(e)
+	}
+}
+
+func (m multiHooks) OnSkip(e Event) {
+	for _, h := range m {
+		h.OnSkip(e)
+	}
+}
+
+func (m multiHooks) OnMisfire(e Event) {
+	for _, h := range m {
+		h.OnMisfire(e)
+	}
+}
+
+// EventStream is Hooks that sends every event on a channel.  Events are dropped, and counted,
+// while the channel is full, so a slow reader never holds up the runner.
+type EventStream struct {
+	// dropped is first so that it is 64-bit aligned for atomic access on 32-bit platforms
+	dropped uint64
+	// C receives the events
+	C <-chan Event
+	c chan Event
+}
+
+// NewEventStream return an event stream whose channel buffers size events
+func NewEventStream(size int) *EventStream {
+	c := make(chan Event, size)
+	return &EventStream{C: c, c: c}
+}
+
+// Dropped return the number of events dropped because the channel was full
+func (s *EventStream) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+func (s *EventStream) send(e Event) {
+	select {
+	case s.c <- e:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+func (s *EventStream) OnScheduled(e Event) { s.send(e) }
+func (s *EventStream) OnStart(e Event)     { s.send(e) }
+func (s *EventStream) OnFinish(e Event)    { s.send(e) }
+func (s *EventStream) OnSkip(e Event)      { s.send(e) }
+func (s *EventStream) OnMisfire(e Event)   { s.send(e) }
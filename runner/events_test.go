@@ -0,0 +1,167 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aalpar/cronfab"
+	"github.com/aalpar/cronfab/cronfabtest"
+)
+
+func nextEvent(t *testing.T, s *EventStream) Event {
+	t.Helper()
+	select {
+	case e := <-s.C:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return Event{}
+}
+
+func expectEvent(t *testing.T, s *EventStream, typ EventType, scheduled time.Time) Event {
+	t.Helper()
+	e := nextEvent(t, s)
+	if e.Type != typ || e.Name != "job" || !e.Scheduled.Equal(scheduled) {
+		t.Fatalf("expected %v for %v, got %v", typ, scheduled, e)
+	}
+	return e
+}
+
+func TestRunner_Events(t *testing.T) {
+	events := NewEventStream(100)
+	clock := cronfabtest.NewFakeClock(testStart)
+	r := New(Options{Clock: clock, Hooks: events})
+	defer r.Stop()
+	job := newBlockingJob()
+	if err := r.Add("job", mustSchedule(t, "* * * * *"), job.run, JobOptions{Overlap: OverlapSkip}); err != nil {
+		t.Fatal(err)
+	}
+	r.Start()
+	t1, t2, t3 := testStart.Add(time.Minute), testStart.Add(2*time.Minute), testStart.Add(3*time.Minute)
+
+	expectEvent(t, events, EventScheduled, t1)
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	// the run starts on its own goroutine, so its event can come either side of the next one
+	byType := map[EventType]Event{}
+	for i := 0; i < 2; i++ {
+		e := nextEvent(t, events)
+		byType[e.Type] = e
+	}
+	if e := byType[EventStart]; !e.Scheduled.Equal(t1) || e.Attempt != 1 || e.Lag() != 0 {
+		t.Errorf("unexpected start event %v", e)
+	}
+	if e := byType[EventScheduled]; !e.Scheduled.Equal(t2) {
+		t.Errorf("unexpected scheduled event %v", e)
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	e := expectEvent(t, events, EventSkip, t2)
+	if e.Reason != SkipOverlap {
+		t.Errorf("expected reason %q, got %q", SkipOverlap, e.Reason)
+	}
+	expectEvent(t, events, EventScheduled, t3)
+
+	job.release <- struct{}{}
+	e = expectEvent(t, events, EventFinish, t1)
+	if e.Err != nil || e.Attempt != 1 || e.Lag() != time.Minute {
+		t.Errorf("unexpected finish event %v", e)
+	}
+	// the stats count the run by the time its finish is sent
+	if s, _ := r.Stats("job"); s.Running != 0 || !s.LastRun.Equal(t1) {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestRunner_MisfireEvents(t *testing.T) {
+	events := NewEventStream(100)
+	clock := cronfabtest.NewFakeClock(testStart)
+	r := New(Options{Clock: clock, Hooks: MultiHooks(NopHooks{}, events), MisfireThreshold: time.Minute})
+	defer r.Stop()
+	job := newBlockingJob()
+	err := r.Add("job", mustSchedule(t, "*/5 * * * *"), job.run, JobOptions{
+		Overlap: OverlapSkip,
+		CatchUp: cronfab.CatchUpAll,
+		LastRun: testStart.Add(-15 * time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Start()
+
+	// the first catch-up firing runs late, the next is late and skipped, the last is on time
+	e := expectEvent(t, events, EventMisfire, testStart.Add(-10*time.Minute))
+	if e.Lag() != 10*time.Minute {
+		t.Errorf("expected a lag of 10m, got %v", e.Lag())
+	}
+	var skipped []Event
+	for len(skipped) < 2 {
+		e := nextEvent(t, events)
+		switch e.Type {
+		case EventMisfire:
+			if !e.Scheduled.Equal(testStart.Add(-5 * time.Minute)) {
+				t.Errorf("unexpected misfire %v", e)
+			}
+		case EventSkip:
+			skipped = append(skipped, e)
+		}
+	}
+	if !skipped[0].Scheduled.Equal(testStart.Add(-5*time.Minute)) || !skipped[1].Scheduled.Equal(testStart) {
+		t.Errorf("unexpected skips %v", skipped)
+	}
+}
+
+func TestRunner_LockSkipEvent(t *testing.T) {
+	events := NewEventStream(100)
+	clock := cronfabtest.NewFakeClock(testStart)
+	r := New(Options{Clock: clock, Hooks: events, Locker: failingLocker{}})
+	defer r.Stop()
+	job := newBlockingJob()
+	if err := r.Add("job", mustSchedule(t, "* * * * *"), job.run, JobOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	r.Start()
+	expectEvent(t, events, EventScheduled, testStart.Add(time.Minute))
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	e := expectEvent(t, events, EventSkip, testStart.Add(time.Minute))
+	if e.Reason != SkipLockError || !errors.Is(e.Err, errLock) {
+		t.Errorf("unexpected skip event %v", e)
+	}
+}
+
+func TestEventStream_Dropped(t *testing.T) {
+	s := NewEventStream(1)
+	for i := 0; i < 3; i++ {
+		s.OnStart(Event{Type: EventStart})
+	}
+	if s.Dropped() != 2 {
+		t.Errorf("expected 2 dropped events, got %d", s.Dropped())
+	}
+}
+
+func TestEvent_String(t *testing.T) {
+	e := Event{
+		Type:      EventFinish,
+		Name:      "job",
+		Scheduled: testStart,
+		Actual:    testStart.Add(1500 * time.Millisecond),
+		Attempt:   2,
+		Err:       errJob,
+	}
+	want := "job finish scheduled 2024-03-01T00:00:00Z lag 1.5s attempt 2: job failed"
+	if got := e.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	e = Event{Type: EventSkip, Name: "job", Scheduled: testStart, Actual: testStart, Reason: SkipOverlap}
+	want = "job skip scheduled 2024-03-01T00:00:00Z lag 0s (overlap)"
+	if got := e.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := EventType(9).String(); got != "EventType(9)" {
+		t.Errorf("unexpected %q", got)
+	}
+}
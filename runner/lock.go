@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aalpar/cronfab"
+)
+
+// Locker lets several replicas running the same jobs agree on which one runs each occurrence.
+// The runner calls TryAcquire before every firing and runs the job only if it returns true.
+//
+// TryAcquire claims the occurrence identified by key and fireTime for ttl.  Implementations must
+// guarantee that, for the same key and fire time, at most one call returns true until ttl has
+// elapsed since that call, across every process sharing the locker.  Claims for different keys or
+// fire times are independent.  Claims are never released early: a job that finishes quickly must
+// still not run again on a slower replica, so ttl should outlast the time it takes every replica to
+// reach the fire time.  A Redis implementation can use SET key NX PX ttl on a key made from key and
+// fireTime; an etcd implementation can create the same key under a lease of ttl only if its
+// revision is zero.
+//
+// An error means the claim is unknown, and the runner skips the occurrence.
+type Locker interface {
+	TryAcquire(key string, fireTime time.Time, ttl time.Duration) (bool, error)
+}
+
+// MemoryLocker is a Locker for replicas in one process
+type MemoryLocker struct {
+	clock  cronfab.Clock
+	mu     sync.Mutex
+	claims map[string]time.Time
+}
+
+// NewMemoryLocker return a memory locker that expires claims by clock.  RealClock is used if
+// clock is nil.
+func NewMemoryLocker(clock cronfab.Clock) *MemoryLocker {
+	if clock == nil {
+		clock = cronfab.RealClock{}
+	}
+	return &MemoryLocker{
+		clock:  clock,
+		claims: map[string]time.Time{},
+	}
+}
+
+func (l *MemoryLocker) TryAcquire(key string, fireTime time.Time, ttl time.Duration) (bool, error) {
+	now := l.clock.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for k, expires := range l.claims {
+		if !now.Before(expires) {
+			delete(l.claims, k)
+		}
+	}
+	k := occurrenceKey(key, fireTime)
+	if _, ok := l.claims[k]; ok {
+		return false, nil
+	}
+	l.claims[k] = now.Add(ttl)
+	return true, nil
+}
+
+// occurrenceKey return a key identifying the occurrence of key at fireTime
+func occurrenceKey(key string, fireTime time.Time) string {
+	return key + "@" + strconv.FormatInt(fireTime.UnixNano(), 10)
+}
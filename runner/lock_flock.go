@@ -0,0 +1,90 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/aalpar/cronfab"
+)
+
+// FileLocker is a Locker for processes on one host sharing a directory.  Each key has a file
+// holding its unexpired claims, one per fire time; flock serializes the check and update of the
+// file.  A claim is refused while the key has an unexpired claim for the same fire time.
+type FileLocker struct {
+	dir   string
+	clock cronfab.Clock
+}
+
+// fileClaim is a claim in a FileLocker file
+type fileClaim struct {
+	FireTime time.Time `json:"fire_time"`
+	Expires  time.Time `json:"expires"`
+}
+
+// NewFileLocker return a file locker keeping its files in dir, which must exist.  RealClock is
+// used if clock is nil.
+func NewFileLocker(dir string, clock cronfab.Clock) *FileLocker {
+	if clock == nil {
+		clock = cronfab.RealClock{}
+	}
+	return &FileLocker{dir: dir, clock: clock}
+}
+
+func (l *FileLocker) TryAcquire(key string, fireTime time.Time, ttl time.Duration) (bool, error) {
+	sum := sha256.Sum256([]byte(key))
+	path := filepath.Join(l.dir, hex.EncodeToString(sum[:])+".lock")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return false, err
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return false, err
+	}
+	var claims []fileClaim
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &claims); err != nil {
+			return false, err
+		}
+	}
+	now := l.clock.Now()
+	live := claims[:0]
+	for _, c := range claims {
+		if !now.Before(c.Expires) {
+			continue
+		}
+		if c.FireTime.Equal(fireTime) {
+			return false, nil
+		}
+		live = append(live, c)
+	}
+	data, err = json.Marshal(append(live, fileClaim{FireTime: fireTime, Expires: now.Add(ttl)}))
+	if err != nil {
+		return false, err
+	}
+	if err := f.Truncate(0); err != nil {
+		return false, err
+	}
+	if _, err := f.WriteAt(data, 0); err != nil {
+		return false, err
+	}
+	if err := f.Sync(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,19 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package runner
+
+import (
+	"os"
+	"testing"
+
+	"github.com/aalpar/cronfab/cronfabtest"
+)
+
+func TestFileLocker(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	clock := cronfabtest.NewFakeClock(testStart)
+	// separate lockers on one directory behave like separate processes
+	testLocker(t, clock, NewFileLocker(dir, clock), NewFileLocker(dir, clock))
+}
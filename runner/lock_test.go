@@ -0,0 +1,150 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aalpar/cronfab/cronfabtest"
+)
+
+// testLocker checks the Locker contract against lockers that share their claims.  Every Locker
+// implementation runs it.
+func testLocker(t *testing.T, clock *cronfabtest.FakeClock, lockers ...Locker) {
+	t.Helper()
+	ft := testStart.Add(time.Minute)
+	acquire := func(l Locker, key string, ft time.Time) bool {
+		t.Helper()
+		ok, err := l.TryAcquire(key, ft, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+	if !acquire(lockers[0], "a", ft) {
+		t.Fatalf("expected the first claim to succeed")
+	}
+	for _, l := range lockers {
+		if acquire(l, "a", ft) {
+			t.Errorf("expected a second claim of the same occurrence to fail")
+		}
+	}
+	if !acquire(lockers[len(lockers)-1], "b", ft) {
+		t.Errorf("expected a claim of another key to succeed")
+	}
+	if !acquire(lockers[len(lockers)-1], "a", ft.Add(time.Minute)) {
+		t.Errorf("expected a claim of another fire time to succeed")
+	}
+	if !acquire(lockers[0], "a", ft.Add(-time.Minute)) {
+		t.Errorf("expected a claim of an earlier fire time to succeed")
+	}
+	for _, l := range lockers {
+		if acquire(l, "a", ft) || acquire(l, "a", ft.Add(time.Minute)) {
+			t.Errorf("expected the claims of both fire times to hold")
+		}
+	}
+	clock.Advance(time.Minute)
+	if !acquire(lockers[0], "b", ft) {
+		t.Errorf("expected the claim to be available once expired")
+	}
+
+	// concurrent claims of one occurrence succeed once
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	q := 0
+	for i := 0; i < 20; i++ {
+		l := lockers[i%len(lockers)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := l.TryAcquire("c", ft, time.Minute)
+			if err != nil {
+				t.Error(err)
+			}
+			if ok {
+				mu.Lock()
+				q++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if q != 1 {
+		t.Errorf("expected one concurrent claim to succeed, got %d", q)
+	}
+}
+
+func TestMemoryLocker(t *testing.T) {
+	clock := cronfabtest.NewFakeClock(testStart)
+	testLocker(t, clock, NewMemoryLocker(clock))
+}
+
+func TestRunner_Locker(t *testing.T) {
+	clock := cronfabtest.NewFakeClock(testStart)
+	locker := NewMemoryLocker(clock)
+	runs := make(chan time.Time, 10)
+	job := func(ctx context.Context) error {
+		ft, _ := FireTime(ctx)
+		runs <- ft
+		return nil
+	}
+	var replicas []*Runner
+	for i := 0; i < 3; i++ {
+		r := New(Options{Clock: clock, Locker: locker})
+		if err := r.Add("job", mustSchedule(t, "*/5 * * * *"), job, JobOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		r.Start()
+		defer r.Stop()
+		replicas = append(replicas, r)
+	}
+	for i := 1; i <= 2; i++ {
+		clock.BlockUntil(3)
+		clock.Advance(5 * time.Minute)
+		if got, want := <-runs, testStart.Add(time.Duration(i)*5*time.Minute); !got.Equal(want) {
+			t.Errorf("expected a run for %v, got %v", want, got)
+		}
+	}
+	clock.BlockUntil(3)
+	select {
+	case ft := <-runs:
+		t.Errorf("unexpected second run for %v", ft)
+	default:
+	}
+	var runsTotal, notAcquired uint64
+	for _, r := range replicas {
+		s, _ := r.Stats("job")
+		runsTotal += s.Runs
+		notAcquired += s.NotAcquired
+	}
+	if runsTotal != 2 || notAcquired != 4 {
+		t.Errorf("expected 2 runs and 4 firings left to other replicas, got %d and %d", runsTotal, notAcquired)
+	}
+}
+
+type failingLocker struct{}
+
+var errLock = errors.New("lock unavailable")
+
+func (failingLocker) TryAcquire(key string, fireTime time.Time, ttl time.Duration) (bool, error) {
+	return false, errLock
+}
+
+func TestRunner_LockerError(t *testing.T) {
+	clock := cronfabtest.NewFakeClock(testStart)
+	r := New(Options{Clock: clock, Locker: failingLocker{}})
+	defer r.Stop()
+	job := func(ctx context.Context) error { return nil }
+	if err := r.Add("job", mustSchedule(t, "* * * * *"), job, JobOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	r.Start()
+	clock.BlockUntil(1)
+	tick(clock, time.Minute)
+	s, _ := r.Stats("job")
+	if s.Runs != 0 || s.NotAcquired != 1 || !errors.Is(s.LockErr, errLock) {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
@@ -0,0 +1,35 @@
+package runner
+
+import (
+	"fmt"
+)
+
+// OverlapPolicy decides what happens when a job fires while a previous run is still going
+type OverlapPolicy int
+
+const (
+	// OverlapAllow starts another run alongside the previous ones
+	OverlapAllow OverlapPolicy = iota
+	// OverlapSkip drops the firing
+	OverlapSkip
+	// OverlapQueue holds up to JobOptions.MaxQueued firings and runs them one at a time once the
+	// current run returns.  Firings beyond that are dropped.
+	OverlapQueue
+	// OverlapCancelPrevious cancels the context of the previous runs and starts a new run
+	// without waiting for them to return
+	OverlapCancelPrevious
+)
+
+func (p OverlapPolicy) String() string {
+	switch p {
+	case OverlapAllow:
+		return "allow"
+	case OverlapSkip:
+		return "skip"
+	case OverlapQueue:
+		return "queue"
+	case OverlapCancelPrevious:
+		return "cancel previous"
+	}
+	return fmt.Sprintf("OverlapPolicy(%d)", int(p))
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// DefaultInitialBackoff is the delay before the first retry when RetryPolicy.InitialBackoff is zero
+const DefaultInitialBackoff = time.Second
+
+// RetryPolicy retries a failed run with exponential backoff.  Retries never go past the job's next
+// fire time: a retry that would start at or after it is not made, and the run gives up instead.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts per fire time, including the first.  Less than 2
+	// means no retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.  DefaultInitialBackoff if zero.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts.  Unlimited if zero.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after each retry.  2 if less than 1.
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction of it, up or down.  Between 0 and 1.
+	Jitter float64
+	// OnGiveUp, if set, is called when a run fails for good, with the job name, the fire time, the
+	// number of attempts made and the last error.
+	OnGiveUp func(name string, fireTime time.Time, attempts int, err error)
+}
+
+// backoff return the delay before retry number attempt, counting from 1.  rnd is a random number
+// in [0, 1) used for jitter.
+func (p RetryPolicy) backoff(attempt int, rnd float64) time.Duration {
+	d := p.InitialBackoff
+	if d <= 0 {
+		d = DefaultInitialBackoff
+	}
+	m := p.Multiplier
+	if m < 1 {
+		m = 2
+	}
+	b := float64(d) * math.Pow(m, float64(attempt-1))
+	if p.MaxBackoff > 0 && b > float64(p.MaxBackoff) {
+		b = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		b += b * p.Jitter * (2*rnd - 1)
+	}
+	if b >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(b)
+}
+
+// runWithRetry runs the job for the firing at ft, retrying as the job's retry policy allows.  It
+// return the error of the run and the finish event of its last attempt, which the caller sends once
+// the run is recorded, or a zero event if that has been sent.
+func (r *Runner) runWithRetry(ctx context.Context, e *entry, ft time.Time) (Event, error) {
+	p := e.opts.Retry
+	deadline, err := e.sched.Next(ft)
+	hasDeadline := err == nil
+	for attempt := 1; ; attempt++ {
+		r.hooks.OnStart(Event{Type: EventStart, Name: e.name, Scheduled: ft, Actual: r.clock.Now(), Attempt: attempt})
+		err := e.job(withAttempt(ctx, attempt))
+		fin := Event{Type: EventFinish, Name: e.name, Scheduled: ft, Actual: r.clock.Now(), Attempt: attempt, Err: err}
+		if err == nil || ctx.Err() != nil {
+			return fin, err
+		}
+		if attempt >= p.MaxAttempts {
+			return fin, r.giveUp(e, ft, attempt, err)
+		}
+		d := p.backoff(attempt, rand.Float64())
+		if hasDeadline && !r.clock.Now().Add(d).Before(deadline) {
+			return fin, r.giveUp(e, ft, attempt, err)
+		}
+		r.hooks.OnFinish(fin)
+		r.mu.Lock()
+		e.stats.Retries++
+		r.mu.Unlock()
+		timer := r.clock.NewTimer(d)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			// the finish of the last attempt has been sent
+			return Event{}, err
+		}
+	}
+}
+
+// giveUp records that the firing of e at ft failed for good
+func (r *Runner) giveUp(e *entry, ft time.Time, attempts int, err error) error {
+	if e.opts.Retry.MaxAttempts > 1 {
+		r.mu.Lock()
+		e.stats.GaveUp++
+		r.mu.Unlock()
+	}
+	if e.opts.Retry.OnGiveUp != nil {
+		e.opts.Retry.OnGiveUp(e.name, ft, attempts, err)
+	}
+	return err
+}
+
+type attemptKey struct{}
+
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// Attempt return the attempt number, counting from 1, of the run a job's context belongs to
+func Attempt(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	for _, tc := range []struct {
+		p       RetryPolicy
+		attempt int
+		rnd     float64
+		want    time.Duration
+	}{
+		{RetryPolicy{}, 1, 0.5, time.Second},
+		{RetryPolicy{}, 3, 0.5, 4 * time.Second},
+		{RetryPolicy{InitialBackoff: time.Minute, Multiplier: 3}, 3, 0.5, 9 * time.Minute},
+		{RetryPolicy{InitialBackoff: time.Minute, MaxBackoff: 3 * time.Minute}, 4, 0.5, 3 * time.Minute},
+		{RetryPolicy{InitialBackoff: time.Minute, Jitter: 0.5}, 1, 0, 30 * time.Second},
+		{RetryPolicy{InitialBackoff: time.Minute, Jitter: 0.5}, 1, 0.75, 75 * time.Second},
+		{RetryPolicy{InitialBackoff: time.Hour}, 100, 0.5, time.Duration(1<<63 - 1)},
+	} {
+		if got := tc.p.backoff(tc.attempt, tc.rnd); got != tc.want {
+			t.Errorf("%+v attempt %d: expected %v, got %v", tc.p, tc.attempt, tc.want, got)
+		}
+	}
+}
+
+var errJob = errors.New("job failed")
+
+// failingJob fails its first failures attempts and reports each attempt number on attempts
+func failingJob(failures int, attempts chan int) Job {
+	return func(ctx context.Context) error {
+		attempt := Attempt(ctx)
+		attempts <- attempt
+		if attempt <= failures {
+			return errJob
+		}
+		return nil
+	}
+}
+
+func TestRunner_Retry(t *testing.T) {
+	attempts := make(chan int, 10)
+	r, clock := startRunner(t, "*/5 * * * *", failingJob(2, attempts), JobOptions{
+		Retry: RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Minute},
+	})
+	defer r.Stop()
+	clock.Advance(5 * time.Minute)
+	for i, d := range []time.Duration{time.Minute, 2 * time.Minute} {
+		if got := <-attempts; got != i+1 {
+			t.Fatalf("expected attempt %d, got %d", i+1, got)
+		}
+		// the schedule's timer and the retry's timer
+		clock.BlockUntil(2)
+		clock.Advance(d)
+	}
+	if got := <-attempts; got != 3 {
+		t.Fatalf("expected attempt 3, got %d", got)
+	}
+	s := waitStats(t, r, func(s Stats) bool { return s.Running == 0 })
+	if s.Retries != 2 || s.Failures != 0 || s.GaveUp != 0 || !s.LastRun.Equal(testStart.Add(5*time.Minute)) {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestRunner_RetryMaxAttempts(t *testing.T) {
+	attempts := make(chan int, 10)
+	gaveUp := make(chan int, 1)
+	r, clock := startRunner(t, "*/5 * * * *", failingJob(10, attempts), JobOptions{
+		Retry: RetryPolicy{
+			MaxAttempts:    2,
+			InitialBackoff: time.Minute,
+			OnGiveUp: func(name string, fireTime time.Time, attempts int, err error) {
+				if name != "job" || !fireTime.Equal(testStart.Add(5*time.Minute)) || !errors.Is(err, errJob) {
+					t.Errorf("unexpected give up %q %v %v", name, fireTime, err)
+				}
+				gaveUp <- attempts
+			},
+		},
+	})
+	defer r.Stop()
+	clock.Advance(5 * time.Minute)
+	<-attempts
+	clock.BlockUntil(2)
+	clock.Advance(time.Minute)
+	<-attempts
+	if got := <-gaveUp; got != 2 {
+		t.Errorf("expected to give up after 2 attempts, got %d", got)
+	}
+	s := waitStats(t, r, func(s Stats) bool { return s.Running == 0 })
+	if s.Retries != 1 || s.Failures != 1 || s.GaveUp != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestRunner_RetryDeadline(t *testing.T) {
+	attempts := make(chan int, 10)
+	gaveUp := make(chan int, 1)
+	r, clock := startRunner(t, "*/5 * * * *", failingJob(10, attempts), JobOptions{
+		Retry: RetryPolicy{
+			MaxAttempts:    10,
+			InitialBackoff: 2 * time.Minute,
+			OnGiveUp: func(name string, fireTime time.Time, attempts int, err error) {
+				gaveUp <- attempts
+			},
+		},
+	})
+	defer r.Stop()
+	clock.Advance(5 * time.Minute)
+	<-attempts
+	clock.BlockUntil(2)
+	clock.Advance(2 * time.Minute)
+	<-attempts
+	// a 4 minute backoff would pass the next fire time 3 minutes away
+	if got := <-gaveUp; got != 2 {
+		t.Errorf("expected to give up after 2 attempts, got %d", got)
+	}
+	s := waitStats(t, r, func(s Stats) bool { return s.Running == 0 })
+	if s.Retries != 1 || s.Failures != 1 || s.GaveUp != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestRunner_NoRetry(t *testing.T) {
+	attempts := make(chan int, 10)
+	gaveUp := make(chan int, 1)
+	r, clock := startRunner(t, "*/5 * * * *", failingJob(10, attempts), JobOptions{
+		Retry: RetryPolicy{OnGiveUp: func(name string, fireTime time.Time, attempts int, err error) {
+			gaveUp <- attempts
+		}},
+	})
+	defer r.Stop()
+	clock.Advance(5 * time.Minute)
+	<-attempts
+	if got := <-gaveUp; got != 1 {
+		t.Errorf("expected to give up after 1 attempt, got %d", got)
+	}
+	s := waitStats(t, r, func(s Stats) bool { return s.Running == 0 })
+	if s.Retries != 0 || s.Failures != 1 || s.GaveUp != 0 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
@@ -0,0 +1,487 @@
+// Package runner runs jobs at the fire times of cronfab schedules.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aalpar/cronfab"
+)
+
+var (
+	ErrDuplicateJob = errors.New("duplicate job name")
+	ErrUnknownJob   = errors.New("unknown job")
+	ErrStopped      = errors.New("runner stopped")
+)
+
+// Job is the work done at each fire time.  ctx is canceled when the runner stops, or when a later
+// firing replaces the run under OverlapCancelPrevious.  FireTime(ctx) return the fire time and
+// Attempt(ctx) the attempt number.
+type Job func(ctx context.Context) error
+
+// Options configures a Runner
+type Options struct {
+	// Clock is the source of time.  RealClock is used if nil.
+	Clock cronfab.Clock
+	// Store persists each job's record.  Add resumes a job's LastRun from its stored record, and
+	// the record is saved whenever the job waits for a new fire time or finishes a run.
+	Store Store
+	// Locker, if set, is consulted before every firing so that only one of several replicas runs
+	// each occurrence.
+	Locker Locker
+	// LockTTL is how long a claim on an occurrence lasts.  If zero, a claim lasts until the job's
+	// following fire time.
+	LockTTL time.Duration
+	// Hooks are told about each job's lifecycle events
+	Hooks Hooks
+	// MisfireThreshold is how late a firing may be before it is a misfire.
+	// DefaultMisfireThreshold if zero.
+	MisfireThreshold time.Duration
+}
+
+// JobOptions configures one job
+type JobOptions struct {
+	// Overlap decides what happens when the job fires while a previous run is still going
+	Overlap OverlapPolicy
+	// MaxQueued is the number of firings OverlapQueue holds while the job is running.
+	// Values less than 1 are treated as 1.
+	MaxQueued int
+	// CatchUp decides which fire times missed since LastRun run when the job starts.  Catch-up
+	// firings go through the overlap policy like any other; use OverlapQueue or OverlapAllow to
+	// run more than one.
+	CatchUp cronfab.CatchUpPolicy
+	// LastRun is the fire time of the job's last run before the runner started.  Nothing is
+	// caught up if it is zero.  If zero, the LastRun of the job's stored record is used.
+	LastRun time.Time
+	// MaxCatchUp caps the missed fire times CatchUpAll runs, as the limit of cronfab.Missed.
+	// CatchUpOnce runs the latest missed fire time however many there are.
+	MaxCatchUp int
+	// LockKey identifies the job to the Locker.  If empty, the job name followed by the
+	// schedule's expression is used.
+	LockKey string
+	// Retry retries failed runs
+	Retry RetryPolicy
+}
+
+// Stats are the counters of one job
+type Stats struct {
+	// Fired is the number of fire times reached
+	Fired uint64
+	// Runs is the number of runs started
+	Runs uint64
+	// Failures is the number of runs that failed, after any retries
+	Failures uint64
+	// Retries is the number of retries made
+	Retries uint64
+	// GaveUp is the number of failed runs that had retries left but stopped, either out of
+	// attempts or because the next retry would pass the next fire time
+	GaveUp uint64
+	// Skipped is the number of firings dropped because the job was running
+	Skipped uint64
+	// Queued is the number of firings held to run after the current run
+	Queued uint64
+	// Canceled is the number of runs canceled by a later firing
+	Canceled uint64
+	// CaughtUp is the number of missed fire times fired at start
+	CaughtUp uint64
+	// CatchUpCapped is the number of catch-ups that met MaxCatchUp and left the later missed fire
+	// times out
+	CatchUpCapped uint64
+	// NotAcquired is the number of firings left to another replica by the Locker
+	NotAcquired uint64
+	// Running is the number of runs in progress
+	Running int
+	// Pending is the number of firings waiting in the queue
+	Pending int
+	// LastRun is the fire time of the last successful run
+	LastRun time.Time
+	// NextRun is the fire time the job is waiting for
+	NextRun time.Time
+	// Err is the error that stopped the job's schedule, if any
+	Err error
+	// StoreErr is the error from the last failed save of the job's record, if any
+	StoreErr error
+	// LockErr is the error from the last failed claim of an occurrence, if any
+	LockErr error
+}
+
+// Runner runs jobs on their schedules.  Jobs added before Start begin at Start; jobs added
+// after Start begin immediately.
+type Runner struct {
+	clock   cronfab.Clock
+	store   Store
+	locker  Locker
+	lockTTL time.Duration
+	hooks   Hooks
+	misfire time.Duration
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	entries map[string]*entry
+	order   []*entry
+	started bool
+	stopped bool
+}
+
+// entry is a job and its state.  All fields after saveMu are guarded by Runner.mu.
+type entry struct {
+	name   string
+	sched  cronfab.Schedule
+	job    Job
+	opts   JobOptions
+	saveMu sync.Mutex
+	stats  Stats
+	queue  []time.Time
+	runs   map[*run]struct{}
+}
+
+// run is one run of a job
+type run struct {
+	cancel   context.CancelFunc
+	canceled bool
+}
+
+// New return a runner that has not been started
+func New(opts Options) *Runner {
+	clock := opts.Clock
+	if clock == nil {
+		clock = cronfab.RealClock{}
+	}
+	hooks := opts.Hooks
+	if hooks == nil {
+		hooks = NopHooks{}
+	}
+	misfire := opts.MisfireThreshold
+	if misfire <= 0 {
+		misfire = DefaultMisfireThreshold
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		clock:   clock,
+		store:   opts.Store,
+		locker:  opts.Locker,
+		lockTTL: opts.LockTTL,
+		hooks:   hooks,
+		misfire: misfire,
+		ctx:     ctx,
+		cancel:  cancel,
+		entries: map[string]*entry{},
+	}
+}
+
+// Add adds a job that runs at the fire times of sched.  Names must be unique.
+func (r *Runner) Add(name string, sched cronfab.Schedule, job Job, opts JobOptions) error {
+	if opts.MaxQueued < 1 {
+		opts.MaxQueued = 1
+	}
+	if r.store != nil && opts.LastRun.IsZero() {
+		rec, err := r.store.Load(name)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		opts.LastRun = rec.LastRun
+	}
+	if opts.LockKey == "" {
+		opts.LockKey = name
+		if expr := scheduleExpr(sched); expr != "" {
+			opts.LockKey += " " + expr
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return ErrStopped
+	}
+	if _, ok := r.entries[name]; ok {
+		return ErrDuplicateJob
+	}
+	e := &entry{
+		name:  name,
+		sched: sched,
+		job:   job,
+		opts:  opts,
+		runs:  map[*run]struct{}{},
+	}
+	e.stats.LastRun = opts.LastRun
+	r.entries[name] = e
+	r.order = append(r.order, e)
+	if r.started {
+		r.startLoop(e)
+	}
+	return nil
+}
+
+// Start starts waiting for fire times.  Calling Start again, or after Stop, does nothing.
+func (r *Runner) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started || r.stopped {
+		return
+	}
+	r.started = true
+	for _, e := range r.order {
+		r.startLoop(e)
+	}
+}
+
+// Stop stops waiting for fire times, cancels the runs in progress and waits for them to return.
+// Queued firings are dropped.
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	r.stopped = true
+	r.cancel()
+	r.mu.Unlock()
+	r.wg.Wait()
+}
+
+// Stats return the counters of the named job
+func (r *Runner) Stats(name string) (Stats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[name]
+	if !ok {
+		return Stats{}, ErrUnknownJob
+	}
+	s := e.stats
+	s.Running = len(e.runs)
+	s.Pending = len(e.queue)
+	return s, nil
+}
+
+// startLoop starts the goroutine that waits for the fire times of e.  r.mu must be held.
+func (r *Runner) startLoop(e *entry) {
+	r.wg.Add(1)
+	go r.loop(e)
+}
+
+func (r *Runner) loop(e *entry) {
+	defer r.wg.Done()
+	t := r.clock.Now()
+	r.catchUp(e, t)
+	for {
+		next, err := e.sched.Next(t)
+		if err != nil {
+			r.mu.Lock()
+			e.stats.Err = err
+			r.mu.Unlock()
+			return
+		}
+		r.mu.Lock()
+		e.stats.NextRun = next
+		r.mu.Unlock()
+		r.save(e)
+		now := r.clock.Now()
+		r.hooks.OnScheduled(Event{Type: EventScheduled, Name: e.name, Scheduled: next, Actual: now})
+		timer := r.clock.NewTimer(next.Sub(now))
+		select {
+		case <-timer.C():
+		case <-r.ctx.Done():
+			timer.Stop()
+			return
+		}
+		r.fire(e, next)
+		t = next
+	}
+}
+
+// catchUp fires the fire times of e missed between its last run and now, as its catch-up policy
+// decides
+func (r *Runner) catchUp(e *entry, now time.Time) {
+	if e.opts.LastRun.IsZero() || e.opts.CatchUp == cronfab.CatchUpSkip {
+		return
+	}
+	var missed []time.Time
+	var err error
+	if e.opts.CatchUp == cronfab.CatchUpOnce {
+		var ft time.Time
+		var ok bool
+		if ft, ok, err = cronfab.LastMissed(e.sched, e.opts.LastRun, now); ok {
+			missed = []time.Time{ft}
+		}
+	} else {
+		var more bool
+		if missed, more, err = cronfab.Missed(e.sched, e.opts.LastRun, now, e.opts.MaxCatchUp); more {
+			r.mu.Lock()
+			e.stats.CatchUpCapped++
+			r.mu.Unlock()
+		}
+	}
+	if err != nil {
+		r.mu.Lock()
+		e.stats.Err = err
+		r.mu.Unlock()
+		return
+	}
+	for _, ft := range e.opts.CatchUp.Apply(missed) {
+		r.mu.Lock()
+		e.stats.CaughtUp++
+		r.mu.Unlock()
+		r.fire(e, ft)
+	}
+}
+
+// fire claims the firing of e at ft and applies the overlap policy of e to it
+func (r *Runner) fire(e *entry, ft time.Time) {
+	now := r.clock.Now()
+	if now.Sub(ft) > r.misfire {
+		r.hooks.OnMisfire(Event{Type: EventMisfire, Name: e.name, Scheduled: ft, Actual: now})
+	}
+	acquired, err := r.acquire(e, ft)
+	if reason := r.dispatch(e, ft, acquired, err); reason != "" {
+		r.hooks.OnSkip(Event{Type: EventSkip, Name: e.name, Scheduled: ft, Actual: r.clock.Now(), Err: err, Reason: reason})
+	}
+}
+
+// dispatch starts, queues or skips the firing of e at ft.  It return the reason for a skip.
+func (r *Runner) dispatch(e *entry, ft time.Time, acquired bool, err error) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e.stats.Fired++
+	if err != nil {
+		e.stats.LockErr = err
+		e.stats.NotAcquired++
+		return SkipLockError
+	}
+	if !acquired {
+		e.stats.NotAcquired++
+		return SkipNotAcquired
+	}
+	if len(e.runs) > 0 {
+		switch e.opts.Overlap {
+		case OverlapSkip:
+			e.stats.Skipped++
+			return SkipOverlap
+		case OverlapQueue:
+			if len(e.queue) >= e.opts.MaxQueued {
+				e.stats.Skipped++
+				return SkipQueueFull
+			}
+			e.queue = append(e.queue, ft)
+			e.stats.Queued++
+			return ""
+		case OverlapCancelPrevious:
+			for rn := range e.runs {
+				if !rn.canceled {
+					rn.canceled = true
+					rn.cancel()
+					e.stats.Canceled++
+				}
+			}
+		}
+	}
+	r.start(e, ft)
+	return ""
+}
+
+// acquire claims the firing of e at ft from the locker, if there is one
+func (r *Runner) acquire(e *entry, ft time.Time) (bool, error) {
+	if r.locker == nil {
+		return true, nil
+	}
+	ttl := r.lockTTL
+	if ttl <= 0 {
+		ttl = time.Minute
+		if next, err := e.sched.Next(ft); err == nil {
+			ttl = next.Sub(ft)
+		}
+	}
+	acquired, err := r.locker.TryAcquire(e.opts.LockKey, ft, ttl)
+	if err != nil {
+		return false, err
+	}
+	return acquired, nil
+}
+
+// start starts a run of e for the firing at ft.  r.mu must be held.
+func (r *Runner) start(e *entry, ft time.Time) {
+	ctx, cancel := context.WithCancel(withFireTime(r.ctx, ft))
+	rn := &run{cancel: cancel}
+	e.runs[rn] = struct{}{}
+	e.stats.Runs++
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		fin, err := r.runWithRetry(ctx, e, ft)
+		cancel()
+		r.finish(e, rn, ft, err)
+		if err == nil {
+			r.save(e)
+		}
+		if fin.Type == EventFinish {
+			r.hooks.OnFinish(fin)
+		}
+	}()
+}
+
+// finish records the end of a run and starts the next queued firing
+func (r *Runner) finish(e *entry, rn *run, ft time.Time, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(e.runs, rn)
+	if err != nil {
+		e.stats.Failures++
+	} else if ft.After(e.stats.LastRun) {
+		e.stats.LastRun = ft
+	}
+	if len(e.queue) > 0 && len(e.runs) == 0 && r.ctx.Err() == nil {
+		ft := e.queue[0]
+		e.queue = e.queue[1:]
+		r.start(e, ft)
+	}
+}
+
+// save saves the record of e to the store, if there is one
+func (r *Runner) save(e *entry) {
+	if r.store == nil {
+		return
+	}
+	// saves of one entry are serialized so that an older record never replaces a newer one
+	e.saveMu.Lock()
+	defer e.saveMu.Unlock()
+	r.mu.Lock()
+	rec := e.record()
+	r.mu.Unlock()
+	err := r.store.Save(rec)
+	r.mu.Lock()
+	e.stats.StoreErr = err
+	r.mu.Unlock()
+}
+
+// record return the persisted state of e.  Runner.mu must be held.
+func (e *entry) record() Record {
+	rec := Record{
+		Name:    e.name,
+		LastRun: e.stats.LastRun,
+		NextRun: e.stats.NextRun,
+	}
+	rec.Expr = scheduleExpr(e.sched)
+	if cl, ok := e.sched.(*cronfab.CompiledLine); ok {
+		rec.Config = cl.Config().Name
+		rec.Line = cl.Line()
+	}
+	return rec
+}
+
+// scheduleExpr return the expression of a schedule that has a String method
+func scheduleExpr(s cronfab.Schedule) string {
+	if str, ok := s.(fmt.Stringer); ok {
+		return str.String()
+	}
+	return ""
+}
+
+type fireTimeKey struct{}
+
+func withFireTime(ctx context.Context, ft time.Time) context.Context {
+	return context.WithValue(ctx, fireTimeKey{}, ft)
+}
+
+// FireTime return the fire time of the run a job's context belongs to
+func FireTime(ctx context.Context) (time.Time, bool) {
+	ft, ok := ctx.Value(fireTimeKey{}).(time.Time)
+	return ft, ok
+}
@@ -0,0 +1,327 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aalpar/cronfab"
+	"github.com/aalpar/cronfab/cronfabtest"
+)
+
+var testStart = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func mustSchedule(t *testing.T, expr string) cronfab.Schedule {
+	t.Helper()
+	cc := cronfab.DefaultCrontabConfig
+	ctl, err := cc.ParseCronTab(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl, err := cc.Compile(ctl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cl
+}
+
+// blockingJob reports each run's fire time on started and returns when released or canceled
+type blockingJob struct {
+	started chan time.Time
+	release chan struct{}
+}
+
+func newBlockingJob() *blockingJob {
+	return &blockingJob{
+		started: make(chan time.Time, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (b *blockingJob) run(ctx context.Context) error {
+	ft, _ := FireTime(ctx)
+	b.started <- ft
+	select {
+	case <-b.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *blockingJob) expectStart(t *testing.T, want time.Time) {
+	t.Helper()
+	select {
+	case got := <-b.started:
+		if !got.Equal(want) {
+			t.Fatalf("expected a run for %v, got %v", want, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the run for %v", want)
+	}
+}
+
+// startRunner starts a runner with one job named "job" on a fake clock.  The caller stops it.
+func startRunner(t *testing.T, expr string, job Job, opts JobOptions) (*Runner, *cronfabtest.FakeClock) {
+	t.Helper()
+	clock := cronfabtest.NewFakeClock(testStart)
+	r := New(Options{Clock: clock, Hooks: newChanges()})
+	if err := r.Add("job", mustSchedule(t, expr), job, opts); err != nil {
+		t.Fatal(err)
+	}
+	r.Start()
+	clock.BlockUntil(1)
+	return r, clock
+}
+
+// tick advances the clock by d and waits for the runner to handle the firing
+func tick(clock *cronfabtest.FakeClock, d time.Duration) {
+	clock.Advance(d)
+	clock.BlockUntil(1)
+}
+
+// changes is Hooks that signal every event, each of which follows a change in the stats
+type changes struct {
+	c chan struct{}
+}
+
+func newChanges() *changes {
+	return &changes{c: make(chan struct{}, 1)}
+}
+
+func (c *changes) signal() {
+	select {
+	case c.c <- struct{}{}:
+	default:
+	}
+}
+
+func (c *changes) OnScheduled(Event) { c.signal() }
+func (c *changes) OnStart(Event)     { c.signal() }
+func (c *changes) OnFinish(Event)    { c.signal() }
+func (c *changes) OnSkip(Event)      { c.signal() }
+func (c *changes) OnMisfire(Event)   { c.signal() }
+
+// waitStats waits until the job's stats satisfy f, checking them after each event.  The runner's
+// hooks must be a *changes.
+func waitStats(t *testing.T, r *Runner, f func(Stats) bool) Stats {
+	t.Helper()
+	ch, ok := r.hooks.(*changes)
+	if !ok {
+		t.Fatal("the runner's hooks are not a *changes")
+	}
+	timeout := time.After(5 * time.Second)
+	for {
+		s, err := r.Stats("job")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f(s) {
+			return s
+		}
+		select {
+		case <-ch.c:
+		case <-timeout:
+			t.Fatalf("timed out waiting for stats, last %+v", s)
+		}
+	}
+}
+
+func TestRunner_OverlapAllow(t *testing.T) {
+	job := newBlockingJob()
+	r, clock := startRunner(t, "* * * * *", job.run, JobOptions{Overlap: OverlapAllow})
+	defer r.Stop()
+	for i := 1; i <= 3; i++ {
+		tick(clock, time.Minute)
+		job.expectStart(t, testStart.Add(time.Duration(i)*time.Minute))
+	}
+	s := waitStats(t, r, func(s Stats) bool { return s.Running == 3 })
+	if s.Fired != 3 || s.Runs != 3 || s.Skipped != 0 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestRunner_OverlapSkip(t *testing.T) {
+	job := newBlockingJob()
+	r, clock := startRunner(t, "*/1 * * * *", job.run, JobOptions{Overlap: OverlapSkip})
+	defer r.Stop()
+	tick(clock, time.Minute)
+	job.expectStart(t, testStart.Add(time.Minute))
+	tick(clock, time.Minute)
+	tick(clock, time.Minute)
+	s, _ := r.Stats("job")
+	if s.Skipped != 2 || s.Runs != 1 || s.Running != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	job.release <- struct{}{}
+	waitStats(t, r, func(s Stats) bool { return s.Running == 0 })
+	tick(clock, time.Minute)
+	job.expectStart(t, testStart.Add(4*time.Minute))
+	s, _ = r.Stats("job")
+	if s.Fired != 4 || s.Skipped != 2 || s.Runs != 2 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestRunner_OverlapQueue(t *testing.T) {
+	job := newBlockingJob()
+	r, clock := startRunner(t, "* * * * *", job.run, JobOptions{Overlap: OverlapQueue, MaxQueued: 2})
+	defer r.Stop()
+	tick(clock, time.Minute)
+	job.expectStart(t, testStart.Add(time.Minute))
+	for i := 0; i < 3; i++ {
+		tick(clock, time.Minute)
+	}
+	s, _ := r.Stats("job")
+	if s.Queued != 2 || s.Pending != 2 || s.Skipped != 1 || s.Runs != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	// queued firings run one at a time, in order
+	job.release <- struct{}{}
+	job.expectStart(t, testStart.Add(2*time.Minute))
+	job.release <- struct{}{}
+	job.expectStart(t, testStart.Add(3*time.Minute))
+	job.release <- struct{}{}
+	s = waitStats(t, r, func(s Stats) bool { return s.Running == 0 })
+	if s.Runs != 3 || s.Pending != 0 || s.Failures != 0 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestRunner_OverlapCancelPrevious(t *testing.T) {
+	job := newBlockingJob()
+	r, clock := startRunner(t, "* * * * *", job.run, JobOptions{Overlap: OverlapCancelPrevious})
+	defer r.Stop()
+	tick(clock, time.Minute)
+	job.expectStart(t, testStart.Add(time.Minute))
+	tick(clock, time.Minute)
+	job.expectStart(t, testStart.Add(2*time.Minute))
+	s := waitStats(t, r, func(s Stats) bool { return s.Running == 1 })
+	if s.Canceled != 1 || s.Failures != 1 || s.Runs != 2 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestRunner_Stop(t *testing.T) {
+	job := newBlockingJob()
+	clock := cronfabtest.NewFakeClock(testStart)
+	r := New(Options{Clock: clock})
+	if err := r.Add("job", mustSchedule(t, "* * * * *"), job.run, JobOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	r.Start()
+	clock.BlockUntil(1)
+	tick(clock, time.Minute)
+	job.expectStart(t, testStart.Add(time.Minute))
+	r.Stop()
+	s, _ := r.Stats("job")
+	if s.Running != 0 || s.Failures != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if clock.Timers() != 0 {
+		t.Errorf("expected no pending timers, got %d", clock.Timers())
+	}
+	if err := r.Add("other", mustSchedule(t, "* * * * *"), job.run, JobOptions{}); !errors.Is(err, ErrStopped) {
+		t.Errorf("expected %v, got %v", ErrStopped, err)
+	}
+}
+
+func TestRunner_Errors(t *testing.T) {
+	r := New(Options{})
+	defer r.Stop()
+	job := func(ctx context.Context) error { return nil }
+	if err := r.Add("job", mustSchedule(t, "* * * * *"), job, JobOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add("job", mustSchedule(t, "* * * * *"), job, JobOptions{}); !errors.Is(err, ErrDuplicateJob) {
+		t.Errorf("expected %v, got %v", ErrDuplicateJob, err)
+	}
+	if _, err := r.Stats("nope"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("expected %v, got %v", ErrUnknownJob, err)
+	}
+}
+
+func TestRunner_AddAfterStart(t *testing.T) {
+	job := newBlockingJob()
+	clock := cronfabtest.NewFakeClock(testStart)
+	r := New(Options{Clock: clock})
+	r.Start()
+	defer r.Stop()
+	if err := r.Add("job", mustSchedule(t, "* * * * *"), job.run, JobOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	clock.BlockUntil(1)
+	tick(clock, time.Minute)
+	job.expectStart(t, testStart.Add(time.Minute))
+}
+
+func TestOverlapPolicy_String(t *testing.T) {
+	for p, want := range map[OverlapPolicy]string{
+		OverlapAllow:          "allow",
+		OverlapSkip:           "skip",
+		OverlapQueue:          "queue",
+		OverlapCancelPrevious: "cancel previous",
+		OverlapPolicy(9):      "OverlapPolicy(9)",
+	} {
+		if got := p.String(); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestRunner_CatchUp(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		policy cronfab.CatchUpPolicy
+		max    int
+		want   []time.Time
+		capped uint64
+	}{
+		{"skip", cronfab.CatchUpSkip, 0, nil, 0},
+		{"once", cronfab.CatchUpOnce, 0, []time.Time{testStart}, 0},
+		// the latest missed fire time, not the last within the cap
+		{"once capped", cronfab.CatchUpOnce, 2, []time.Time{testStart}, 0},
+		{"all", cronfab.CatchUpAll, 0, []time.Time{
+			testStart.Add(-15 * time.Minute),
+			testStart.Add(-10 * time.Minute),
+			testStart.Add(-5 * time.Minute),
+			testStart,
+		}, 0},
+		{"all capped", cronfab.CatchUpAll, 2, []time.Time{
+			testStart.Add(-15 * time.Minute),
+			testStart.Add(-10 * time.Minute),
+		}, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			job := newBlockingJob()
+			r, _ := startRunner(t, "*/5 * * * *", job.run, JobOptions{
+				Overlap:    OverlapAllow,
+				CatchUp:    tc.policy,
+				LastRun:    testStart.Add(-20 * time.Minute),
+				MaxCatchUp: tc.max,
+			})
+			defer r.Stop()
+			got := map[time.Time]bool{}
+			for range tc.want {
+				select {
+				case ft := <-job.started:
+					got[ft] = true
+				case <-time.After(5 * time.Second):
+					t.Fatal("timed out waiting for a catch-up run")
+				}
+			}
+			for _, ft := range tc.want {
+				if !got[ft] {
+					t.Errorf("expected a run for %v, got %v", ft, got)
+				}
+			}
+			s, _ := r.Stats("job")
+			if s.CaughtUp != uint64(len(tc.want)) || s.Runs != uint64(len(tc.want)) || s.CatchUpCapped != tc.capped {
+				t.Errorf("unexpected stats %+v", s)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aalpar/cronfab"
+)
+
+var (
+	ErrNotFound      = errors.New("record not found")
+	ErrUnknownConfig = errors.New("unknown crontab config")
+)
+
+// Record is the persisted state of a scheduled job
+type Record struct {
+	// Name is the job name
+	Name string `json:"name"`
+	// Expr is the schedule's expression as its String method writes it, when it has one
+	Expr string `json:"expr,omitempty"`
+	// Config is the Name of the CrontabConfig the line belongs to
+	Config string `json:"config,omitempty"`
+	// Line is the parsed schedule
+	Line cronfab.CrontabLine `json:"line,omitempty"`
+	// LastRun is the fire time of the last successful run
+	LastRun time.Time `json:"last_run"`
+	// NextRun is the next fire time the job was waiting for
+	NextRun time.Time `json:"next_run"`
+}
+
+// Schedule compiles the record's line against the config, among configs, that the record names.
+// The expression is parsed if the record has no line.
+func (rec Record) Schedule(configs ...*cronfab.CrontabConfig) (*cronfab.CompiledLine, error) {
+	for _, cc := range configs {
+		if cc.Name != rec.Config {
+			continue
+		}
+		ctl := rec.Line
+		if ctl == nil {
+			var err error
+			ctl, err = cc.ParseCronTab(rec.Expr)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return cc.Compile(ctl)
+	}
+	return nil, ErrUnknownConfig
+}
+
+// Store persists job records.  Implementations must be safe for concurrent use.
+type Store interface {
+	// Load return the record for the named job, or ErrNotFound
+	Load(name string) (Record, error)
+	// Save creates or replaces the record with the same name
+	Save(rec Record) error
+	// Delete removes the named record.  Deleting a missing record is not an error.
+	Delete(name string) error
+	// List return all records ordered by name
+	List() ([]Record, error)
+}
+
+// MemoryStore is a Store that keeps records in memory
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// NewMemoryStore return an empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]Record{}}
+}
+
+func (s *MemoryStore) Load(name string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[name]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return rec, nil
+}
+
+func (s *MemoryStore) Save(rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[rec.Name] = rec
+	return nil
+}
+
+func (s *MemoryStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, name)
+	return nil
+}
+
+func (s *MemoryStore) List() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedRecords(s.records), nil
+}
+
+// FileStore is a Store that keeps all records in one JSON file.  Every change rewrites the file
+// through a temporary file and a rename, so a crash leaves either the old or the new contents.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore return a store backed by the file at path.  The file is created on the first Save.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Load(name string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records, err := s.read()
+	if err != nil {
+		return Record{}, err
+	}
+	rec, ok := records[name]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return rec, nil
+}
+
+func (s *FileStore) Save(rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records, err := s.read()
+	if err != nil {
+		return err
+	}
+	records[rec.Name] = rec
+	return s.write(records)
+}
+
+func (s *FileStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := records[name]; !ok {
+		return nil
+	}
+	delete(records, name)
+	return s.write(records)
+}
+
+func (s *FileStore) List() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return sortedRecords(records), nil
+}
+
+// read return the records in the file.  A missing file has no records.
+func (s *FileStore) read() (map[string]Record, error) {
+	records := map[string]Record{}
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []Record
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	for _, rec := range list {
+		records[rec.Name] = rec
+	}
+	return records, nil
+}
+
+// write replaces the file with the records
+func (s *FileStore) write(records map[string]Record) error {
+	data, err := json.MarshalIndent(sortedRecords(records), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data)
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory as path, syncs it and
+// renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	// make the rename durable; not every platform can sync a directory, so errors are ignored
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+func sortedRecords(records map[string]Record) []Record {
+	list := make([]Record, 0, len(records))
+	for _, rec := range records {
+		list = append(list, rec)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
@@ -0,0 +1,186 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aalpar/cronfab"
+	"github.com/aalpar/cronfab/cronfabtest"
+)
+
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+	if _, err := s.Load("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+	ctl, err := cronfab.DefaultCrontabConfig.ParseCronTab("*/5 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := Record{Name: "b", Expr: "*/5 * * * *", Config: "default", Line: ctl, LastRun: testStart, NextRun: testStart.Add(5 * time.Minute)}
+	a := Record{Name: "a", Expr: "@daily"}
+	for _, rec := range []Record{b, a} {
+		if err := s.Save(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.Load("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, b) {
+		t.Errorf("expected %+v, got %+v", b, got)
+	}
+
+	b.LastRun = b.NextRun
+	if err := s.Save(b); err != nil {
+		t.Fatal(err)
+	}
+	list, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "a" || list[1].Name != "b" || !list[1].LastRun.Equal(b.LastRun) {
+		t.Errorf("unexpected list %+v", list)
+	}
+
+	if err := s.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("a"); err != nil {
+		t.Errorf("unexpected error deleting a missing record: %v", err)
+	}
+	if _, err := s.Load("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "cronfab-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestFileStore(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "jobs.json")
+	testStore(t, NewFileStore(path))
+
+	// a new store on the same file sees the saved records
+	list, err := NewFileStore(path).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Name != "b" {
+		t.Errorf("unexpected list %+v", list)
+	}
+	// no temporary files are left behind
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected only the store file, got %d files", len(files))
+	}
+}
+
+func TestFileStore_Corrupt(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "jobs.json")
+	if err := ioutil.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileStore(path).List(); err == nil {
+		t.Errorf("expected an error reading a corrupt file")
+	}
+}
+
+func TestRecord_Schedule(t *testing.T) {
+	rec := Record{Name: "a", Expr: "0 0 0 * * * *", Config: "second"}
+	cl, err := rec.Schedule(cronfab.DefaultCrontabConfig, cronfab.SecondCrontabConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cl.Config() != cronfab.SecondCrontabConfig {
+		t.Errorf("expected the second config")
+	}
+	next, err := cl.Next(testStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := testStart.AddDate(0, 0, 1); !next.Equal(want) {
+		t.Errorf("expected %v, got %v", want, next)
+	}
+	rec.Config = "lunar"
+	if _, err := rec.Schedule(cronfab.DefaultCrontabConfig); !errors.Is(err, ErrUnknownConfig) {
+		t.Errorf("expected %v, got %v", ErrUnknownConfig, err)
+	}
+}
+
+func TestRunner_StoreResume(t *testing.T) {
+	store := NewMemoryStore()
+	clock := cronfabtest.NewFakeClock(testStart)
+	done := make(chan time.Time, 10)
+	job := func(ctx context.Context) error {
+		ft, _ := FireTime(ctx)
+		done <- ft
+		return nil
+	}
+
+	r := New(Options{Clock: clock, Store: store, Hooks: newChanges()})
+	if err := r.Add("job", mustSchedule(t, "*/5 * * * *"), job, JobOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	r.Start()
+	clock.BlockUntil(1)
+	tick(clock, 5*time.Minute)
+	<-done
+	waitStats(t, r, func(s Stats) bool { return s.LastRun.Equal(testStart.Add(5 * time.Minute)) })
+	r.Stop()
+
+	rec, err := store.Load("job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Expr != rec.Line.String() || rec.Config != "default" || rec.Line == nil {
+		t.Errorf("unexpected record %+v", rec)
+	}
+	if want := testStart.Add(5 * time.Minute); !rec.LastRun.Equal(want) {
+		t.Errorf("expected last run %v, got %v", want, rec.LastRun)
+	}
+	if want := testStart.Add(10 * time.Minute); !rec.NextRun.Equal(want) {
+		t.Errorf("expected next run %v, got %v", want, rec.NextRun)
+	}
+
+	// after 20 minutes down, a new runner catches up from the stored last run
+	clock.Advance(20 * time.Minute)
+	sched, err := rec.Schedule(cronfab.DefaultCrontabConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r = New(Options{Clock: clock, Store: store, Hooks: newChanges()})
+	defer r.Stop()
+	if err := r.Add("job", sched, job, JobOptions{Overlap: OverlapAllow, CatchUp: cronfab.CatchUpAll}); err != nil {
+		t.Fatal(err)
+	}
+	r.Start()
+	s := waitStats(t, r, func(s Stats) bool { return s.Runs == 4 && s.Running == 0 })
+	if s.CaughtUp != 4 || !s.LastRun.Equal(testStart.Add(25*time.Minute)) {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
@@ -0,0 +1,131 @@
+package cronfab
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnsatisfiable = errors.New("unsatisfiable expression")
+	ErrFieldCount    = errors.New("wrong number of fields")
+)
+
+// SatisfiableSpan is how far ahead Satisfiable looks for a match.  Between 1901 and 2099 the
+// Gregorian calendar repeats its weekdays and leap days every 28 years, so a line built from the
+// standard fields that has no match within this span never matches.  Fields like years, whose
+// values do not all come round within the span, are searched over the values the line allows
+// instead.
+const SatisfiableSpan = 28 * 366 * 24 * time.Hour
+
+// satisfiableMaxIt is the iteration budget for each search made by Satisfiable.  The span, not the
+// budget, is meant to end the search.
+const satisfiableMaxIt = 1 << 20
+
+// satisfiableStart is the instant Satisfiable searches from
+var satisfiableStart = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Satisfiable return nil if some instant within SatisfiableSpan, or within the years the line
+// allows when it restricts a year field, matches the crontab line.  If none
+// does, the error is an *ErrorUnsatisfiable naming the fields that conflict: those whose
+// constraints, if relaxed to '*', would let the line match.
+func (cc *CrontabConfig) Satisfiable(ctl CrontabLine) error {
+	if len(ctl) != len(cc.Fields) {
+		return ErrFieldCount
+	}
+	ok, err := cc.matchesWithin(ctl)
+	if err != nil || ok {
+		return err
+	}
+	var conflicting, constrained []string
+	for i := range ctl {
+		if cc.isFullField(i, ctl[i]) {
+			continue
+		}
+		constrained = append(constrained, cc.Fields[i].Name)
+		relaxed := make(CrontabLine, len(ctl))
+		copy(relaxed, ctl)
+		relaxed[i] = [][3]int{{cc.Fields[i].Min, cc.Fields[i].Max, 1}}
+		ok, err := cc.matchesWithin(relaxed)
+		if err != nil {
+			return err
+		}
+		if ok {
+			conflicting = append(conflicting, cc.Fields[i].Name)
+		}
+	}
+	if len(conflicting) == 0 {
+		// no single field is to blame
+		conflicting = constrained
+	}
+	return &ErrorUnsatisfiable{Fields: conflicting}
+}
+
+// matchesWithin return true if the crontab line matches within the window Satisfiable searches
+func (cc *CrontabConfig) matchesWithin(ctl CrontabLine) (bool, error) {
+	start, end := cc.window(ctl)
+	if !end.After(start) {
+		return false, nil
+	}
+	// Next searches after its start, so start just before the window
+	_, err := cc.NextWithOptions(ctl, start.Add(-1), NextOptions{MaxIt: satisfiableMaxIt, MaxSpan: end.Sub(start)})
+	if errors.Is(err, ErrMaxSpan) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// window return the instants Satisfiable searches between: SatisfiableSpan from satisfiableStart,
+// or the periods a restricted field of the coarsest unit allows when that field has more values
+// than there are periods in the span.  Such a field, like a year, never comes round.
+func (cc *CrontabConfig) window(ctl CrontabLine) (time.Time, time.Time) {
+	start, end := satisfiableStart, satisfiableStart.Add(SatisfiableSpan)
+	top := cc.Units[len(cc.Units)-1]
+	periods := periodsWithin(top, satisfiableStart, end)
+	narrowed := false
+	for _, i := range cc.FieldUnits[top.String()] {
+		f := cc.Fields[i]
+		if f.Max-f.Min < periods || cc.isFullField(i, ctl[i]) {
+			continue
+		}
+		fs := NewFieldSet(f.Min, f.Max, ctl[i])
+		first, roll := fs.Ceil(f.Min)
+		if roll {
+			return start, start
+		}
+		last := f.Max
+		for !fs.Contains(last) {
+			last--
+		}
+		x0 := f.GetIndex(satisfiableStart)
+		s := top.Trunc(top.Add(satisfiableStart, first-x0))
+		e := top.Trunc(top.Add(satisfiableStart, last+1-x0))
+		if !narrowed || s.After(start) {
+			start = s
+		}
+		if !narrowed || e.Before(end) {
+			end = e
+		}
+		narrowed = true
+	}
+	return start, end
+}
+
+// periodsWithin return the number of periods of unit u that start after t and no later than end
+func periodsWithin(u Unit, t, end time.Time) int {
+	n := 0
+	for t = u.Add(u.Trunc(t), 1); !t.After(end); t = u.Add(t, 1) {
+		n++
+	}
+	return n
+}
+
+// isFullField return true if the crontab field allows every value of field i
+func (cc *CrontabConfig) isFullField(i int, cf CrontabField) bool {
+	for x := cc.Fields[i].Min; x <= cc.Fields[i].Max; x++ {
+		y, roll := cf.Ceil(x)
+		if roll || y != x {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package cronfab
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSatisfiable(t *testing.T) {
+	tcases := []struct {
+		cc     *CrontabConfig
+		in     string
+		fields []string
+	}{
+		{cc: DefaultCrontabConfig, in: "*/5 * * * *"},
+		{cc: DefaultCrontabConfig, in: "0 0 29 feb *"},
+		{cc: DefaultCrontabConfig, in: "0 0 29 feb mon"},
+		{cc: DefaultCrontabConfig, in: "0 0 31 jan-dec *"},
+		{cc: DefaultCrontabConfig, in: "0 0 30 feb *", fields: []string{"day of month", "month"}},
+		{cc: DefaultCrontabConfig, in: "0 0 31 apr,jun *", fields: []string{"day of month", "month"}},
+		{cc: DefaultCrontabConfig, in: "0 0 30,31 feb mon", fields: []string{"day of month", "month"}},
+		{cc: SecondCrontabConfig, in: "0 0 0 1 * * *"},
+		{cc: SecondCrontabConfig, in: "0 0 0 1 5 * *", fields: []string{"day of month", "week of month"}},
+		{cc: SecondCrontabConfig, in: "0 0 0 31 * feb *", fields: []string{"day of month", "month"}},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.in, func(t *testing.T) {
+			cl, err := tc.cc.ParseCronTab(tc.in)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			err = tc.cc.Satisfiable(cl)
+			if tc.fields == nil {
+				if err != nil {
+					t.Errorf("err: %v", err)
+				}
+				return
+			}
+			var unsat *ErrorUnsatisfiable
+			if !errors.As(err, &unsat) {
+				t.Fatalf("expected *ErrorUnsatisfiable, got %v", err)
+			}
+			if !reflect.DeepEqual(unsat.Fields, tc.fields) {
+				t.Errorf("unexpected fields: %q != %q", unsat.Fields, tc.fields)
+			}
+			if !errors.Is(err, ErrUnsatisfiable) {
+				t.Errorf("expected errors.Is(err, ErrUnsatisfiable)")
+			}
+		})
+	}
+}
+
+func TestSatisfiable_FieldCount(t *testing.T) {
+	cl, err := DefaultCrontabConfig.ParseCronTab("0 0 30")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := DefaultCrontabConfig.Satisfiable(cl); err != ErrFieldCount {
+		t.Errorf("expected ErrFieldCount, got %v", err)
+	}
+}
+
+func TestErrorUnsatisfiable_Error(t *testing.T) {
+	e := &ErrorUnsatisfiable{Fields: []string{"day of month", "month"}}
+	s := e.Error()
+	if s != "unsatisfiable expression: conflicting fields day of month, month" {
+		t.Errorf("unexpected: %q", s)
+	}
+}
+
+func TestParseCrontab_Strict(t *testing.T) {
+	cc := MustCrontabConfig(DefaultCrontabConfig.Fields)
+	cc.Aliases = DefaultCrontabConfig.Aliases
+	cc.Strict = true
+	if _, err := cc.ParseCronTab("0 0 29 feb *"); err != nil {
+		t.Errorf("err: %v", err)
+	}
+	if _, err := cc.ParseCronTab("@monthly"); err != nil {
+		t.Errorf("err: %v", err)
+	}
+	if _, err := cc.ParseCronTab("0 0 30 feb *"); !errors.Is(err, ErrUnsatisfiable) {
+		t.Errorf("expected ErrUnsatisfiable, got %v", err)
+	}
+	if _, err := cc.ParseCronTab("0 0 30"); err != ErrFieldCount {
+		t.Errorf("expected ErrFieldCount, got %v", err)
+	}
+	// the non-strict config still accepts the line
+	if _, err := DefaultCrontabConfig.ParseCronTab("0 0 30 feb *"); err != nil {
+		t.Errorf("err: %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+package cronfab
+
+import (
+	"time"
+)
+
+// Schedule is anything that can compute fire times.  *CompiledLine and *IntervalSchedule are
+// Schedules.
+type Schedule interface {
+	// Next return the next fire time after t
+	Next(t time.Time) (time.Time, error)
+}
@@ -0,0 +1,47 @@
+package cronfab
+
+import (
+	"context"
+	"time"
+)
+
+// SearchStats is the cost of one search for a next time
+type SearchStats struct {
+	// Iterations is the number of search iterations, the count that is checked against MaxIt
+	Iterations int
+	// Rolls counts, for each unit of the config's Units (finest first), the iterations that moved
+	// the time forward at that unit
+	Rolls []int
+	// Elapsed is the wall time the search took
+	Elapsed time.Duration
+}
+
+// SearchObserver is told the cost of searches.  ObserveSearch is called synchronously after each
+// search with the line searched, its stats and the search error, so it must be quick and safe for
+// concurrent use.
+type SearchObserver interface {
+	ObserveSearch(ctl CrontabLine, stats SearchStats, err error)
+}
+
+// NextWithStats is like NextWithOptions but also return the cost of the search.
+func (cc *CrontabConfig) NextWithStats(ctl CrontabLine, n time.Time, opts NextOptions) (time.Time, SearchStats, error) {
+	cl, err := cc.compiled(ctl)
+	if err != nil {
+		return n, SearchStats{}, err
+	}
+	return cl.searchWithStats(ctl, n, opts)
+}
+
+// NextWithStats is like NextWithOptions but also return the cost of the search.
+func (cl *CompiledLine) NextWithStats(n time.Time, opts NextOptions) (time.Time, SearchStats, error) {
+	return cl.searchWithStats(cl.line, n, opts)
+}
+
+func (cl *CompiledLine) searchWithStats(ctl CrontabLine, n time.Time, opts NextOptions) (time.Time, SearchStats, error) {
+	var st SearchStats
+	t, err := cl.find(context.Background(), n, opts, &st)
+	if cl.cc.Observer != nil {
+		cl.cc.Observer.ObserveSearch(ctl, st, err)
+	}
+	return t, st, err
+}
@@ -0,0 +1,110 @@
+package cronfab
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNextWithStats(t *testing.T) {
+	cc := DefaultCrontabConfig
+	ctl, err := cc.ParseCronTab("30 4 1 * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	next, st, err := cc.NextWithStats(ctl, start, NextOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 4, 1, 4, 30, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("expected %v, got %v", want, next)
+	}
+	if len(st.Rolls) != len(cc.Units) {
+		t.Fatalf("expected %d rolls, got %v", len(cc.Units), st.Rolls)
+	}
+	// every iteration but the last, which matched, moved the time at one unit
+	q := 0
+	for _, r := range st.Rolls {
+		q += r
+	}
+	if st.Iterations == 0 || q != st.Iterations-1 {
+		t.Errorf("expected rolls to sum to %d, got %v", st.Iterations-1, st.Rolls)
+	}
+
+	cl, err := cc.Compile(ctl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next2, st2, err := cl.NextWithStats(start, NextOptions{})
+	if err != nil || !next2.Equal(next) || st2.Iterations != st.Iterations {
+		t.Errorf("expected %v in %d iterations, got %v in %d, %v", next, st.Iterations, next2, st2.Iterations, err)
+	}
+}
+
+func TestNextWithStats_Limit(t *testing.T) {
+	cc := DefaultCrontabConfig
+	ctl, err := cc.ParseCronTab("0 0 30 feb *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, st, err := cc.NextWithStats(ctl, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), NextOptions{MaxIt: 50})
+	var limit *ErrorSearchLimit
+	if !errors.As(err, &limit) {
+		t.Fatalf("expected a search limit error, got %v", err)
+	}
+	if st.Iterations != limit.Iterations {
+		t.Errorf("expected %d iterations, got %d", limit.Iterations, st.Iterations)
+	}
+	if _, _, err := cc.NextWithStats(CrontabLine{}, time.Time{}, NextOptions{}); !errors.Is(err, ErrFieldCount) {
+		t.Errorf("expected %v, got %v", ErrFieldCount, err)
+	}
+}
+
+type recordingObserver struct {
+	mu    sync.Mutex
+	lines []string
+	stats []SearchStats
+	errs  []error
+}
+
+func (o *recordingObserver) ObserveSearch(ctl CrontabLine, st SearchStats, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.lines = append(o.lines, ctl.String())
+	o.stats = append(o.stats, st)
+	o.errs = append(o.errs, err)
+}
+
+func TestSearchObserver(t *testing.T) {
+	o := &recordingObserver{}
+	cc := MustCrontabConfig(DefaultCrontabConfig.Fields)
+	cc.Observer = o
+	ctl, err := cc.ParseCronTab("*/15 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	if _, err := cc.Next(ctl, start); err != nil {
+		t.Fatal(err)
+	}
+	cl, err := cc.Compile(ctl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cl.Next(start); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cc.NextWithStats(ctl, start, NextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(o.lines) != 3 {
+		t.Fatalf("expected 3 observed searches, got %d", len(o.lines))
+	}
+	for i := range o.lines {
+		if o.lines[i] != ctl.String() || o.stats[i].Iterations == 0 || o.errs[i] != nil {
+			t.Errorf("unexpected observation %q %+v %v", o.lines[i], o.stats[i], o.errs[i])
+		}
+	}
+}
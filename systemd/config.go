@@ -0,0 +1,124 @@
+// Package systemd converts between systemd calendar expressions, as used by OnCalendar= in timer
+// units, and cronfab crontab lines.
+package systemd
+
+import (
+	"time"
+
+	"github.com/aalpar/cronfab"
+)
+
+// Field names of Config.  Format also recognizes them in other configs.
+const (
+	FieldSecond      = "second"
+	FieldMinute      = "minute"
+	FieldHour        = "hour"
+	FieldDayOfMonth  = "day of month"
+	FieldDaysFromEnd = "days from end of month"
+	FieldMonth       = "month"
+	FieldYear        = "year"
+	FieldDayOfWeek   = "day of week"
+)
+
+// Year range of systemd calendar expressions
+const (
+	MinYear = 1970
+	MaxYear = 2199
+)
+
+// indexes of the fields of Config
+const (
+	second = iota
+	minute
+	hour
+	dayOfMonth
+	daysFromEnd
+	month
+	year
+	dayOfWeek
+)
+
+// Config has the fields of a systemd calendar expression.  Days from end of month counts back from
+// the last day of the month, which is 1, and carries the "~" syntax.  All fields must match, as in
+// systemd, so a day of week and a day of month both apply.
+var Config = cronfab.MustCrontabConfig([]cronfab.FieldConfig{
+	{
+		Unit: cronfab.SecondUnit{},
+		Name: FieldSecond,
+		Min:  0,
+		Max:  59,
+		GetIndex: func(t time.Time) int {
+			return t.Second()
+		},
+	},
+	{
+		Unit: cronfab.MinuteUnit{},
+		Name: FieldMinute,
+		Min:  0,
+		Max:  59,
+		GetIndex: func(t time.Time) int {
+			return t.Minute()
+		},
+	},
+	{
+		Unit: cronfab.HourUnit{},
+		Name: FieldHour,
+		Min:  0,
+		Max:  23,
+		GetIndex: func(t time.Time) int {
+			return t.Hour()
+		},
+	},
+	{
+		Unit: cronfab.DayUnit{},
+		Name: FieldDayOfMonth,
+		Min:  1,
+		Max:  31,
+		GetIndex: func(t time.Time) int {
+			return t.Day()
+		},
+	},
+	{
+		Unit: cronfab.DayUnit{},
+		Name: FieldDaysFromEnd,
+		Min:  1,
+		Max:  31,
+		GetIndex: func(t time.Time) int {
+			last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+			return last - t.Day() + 1
+		},
+	},
+	{
+		Unit:       cronfab.MonthUnit{},
+		Name:       FieldMonth,
+		RangeNames: []string{"january", "february", "march", "april", "may", "june", "july", "august", "september", "october", "november", "december"},
+		Min:        1,
+		Max:        12,
+		GetIndex: func(t time.Time) int {
+			return int(t.Month())
+		},
+	},
+	{
+		Unit: cronfab.YearUnit{},
+		Name: FieldYear,
+		Min:  MinYear,
+		Max:  MaxYear,
+		GetIndex: func(t time.Time) int {
+			return t.Year()
+		},
+	},
+	{
+		Unit:       cronfab.DayUnit{},
+		Name:       FieldDayOfWeek,
+		RangeNames: []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"},
+		Min:        0,
+		Max:        6,
+		GetIndex: func(t time.Time) int {
+			return int(t.Weekday())
+		},
+	},
+})
+
+func init() {
+	Config.Name = "systemd"
+}
@@ -0,0 +1,194 @@
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aalpar/cronfab"
+)
+
+var (
+	ErrUntranslatable = errors.New("cannot translate to a calendar expression")
+	ErrFieldCount     = errors.New("line does not have a field for each field of the config")
+)
+
+// ErrorUntranslatable is returned by Format for a line that has no calendar expression.
+// Parts names each field, with its value, that can't be written.
+type ErrorUntranslatable struct {
+	Parts []string
+}
+
+func (e *ErrorUntranslatable) Error() string {
+	return fmt.Sprintf("%v: %s", ErrUntranslatable, strings.Join(e.Parts, "; "))
+}
+
+// Unwrap return ErrUntranslatable so that errors.Is(err, ErrUntranslatable) works
+func (e *ErrorUntranslatable) Unwrap() error {
+	return ErrUntranslatable
+}
+
+// fieldIndex maps the field names Format knows to the fields of Config
+var fieldIndex = map[string]int{
+	FieldSecond:      second,
+	FieldMinute:      minute,
+	FieldHour:        hour,
+	FieldDayOfMonth:  dayOfMonth,
+	FieldDaysFromEnd: daysFromEnd,
+	FieldMonth:       month,
+	FieldYear:        year,
+	FieldDayOfWeek:   dayOfWeek,
+}
+
+var weekdayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// Format return the calendar expression of a line of cc, like "Mon..Fri *-*-* 09:00:00".  Fields
+// are matched to the calendar expression by name; a missing second, minute or hour is 00 and a
+// missing date field is "*".  Fields with other names, like week of month, can only be written if
+// they allow every value.  Otherwise the error is an *ErrorUntranslatable listing them.
+func Format(cc *cronfab.CrontabConfig, ctl cronfab.CrontabLine) (string, error) {
+	if len(ctl) != len(cc.Fields) {
+		return "", ErrFieldCount
+	}
+	var fields [dayOfWeek + 1]cronfab.CrontabField
+	var present [dayOfWeek + 1]bool
+	var parts []string
+	weekStart := 0
+	for i, f := range cc.Fields {
+		cf := ctl.GetField(i)
+		full := cronfab.NewFieldSet(f.Min, f.Max, cf).Len() == f.Max-f.Min+1
+		k, ok := fieldIndex[f.Name]
+		if !ok {
+			if !full {
+				parts = append(parts, f.Name+" "+cf.String())
+			}
+			continue
+		}
+		present[k] = true
+		if k == dayOfWeek {
+			weekStart = f.Min
+		}
+		if !full {
+			fields[k] = cf
+		}
+	}
+	if fields[dayOfMonth] != nil && fields[daysFromEnd] != nil {
+		parts = append(parts, fmt.Sprintf("%s %v with %s %v", FieldDayOfMonth, fields[dayOfMonth], FieldDaysFromEnd, fields[daysFromEnd]))
+	}
+	if len(parts) > 0 {
+		return "", &ErrorUntranslatable{Parts: parts}
+	}
+
+	var sb strings.Builder
+	if fields[dayOfWeek] != nil {
+		sb.WriteString(formatWeekdays(fields[dayOfWeek], weekStart))
+		sb.WriteByte(' ')
+	}
+	sb.WriteString(formatComponent(fields[year], MinYear, MaxYear, 4))
+	sb.WriteByte('-')
+	sb.WriteString(formatComponent(fields[month], 1, 12, 2))
+	if fields[daysFromEnd] != nil {
+		sb.WriteByte('~')
+		sb.WriteString(formatValues(fields[daysFromEnd], 1, 31))
+	} else {
+		sb.WriteByte('-')
+		sb.WriteString(formatComponent(fields[dayOfMonth], 1, 31, 2))
+	}
+	for _, k := range []int{hour, minute, second} {
+		if k == hour {
+			sb.WriteByte(' ')
+		} else {
+			sb.WriteByte(':')
+		}
+		if !present[k] {
+			sb.WriteString("00")
+			continue
+		}
+		sb.WriteString(formatComponent(fields[k], 0, Config.Fields[k].Max, 2))
+	}
+	return sb.String(), nil
+}
+
+// formatComponent writes a field that has values from min to max.  A nil field is "*".
+func formatComponent(cf cronfab.CrontabField, min, max, width int) string {
+	if cf == nil {
+		return "*"
+	}
+	var q []string
+	for i := range cf {
+		c := cf.GetConstraint(i)
+		lo, hi, step := c.GetMin(), c.GetMax(), c.GetStep()
+		if lo < min {
+			lo += ((min - lo + step - 1) / step) * step
+		}
+		if hi > max {
+			hi = max
+		}
+		switch {
+		case lo > hi:
+		case lo == hi || lo+step > hi:
+			q = append(q, pad(lo, width))
+		case step == 1 && lo == min && hi == max:
+			q = append(q, "*")
+		case step == 1:
+			q = append(q, pad(lo, width)+".."+pad(hi, width))
+		case hi+step > max:
+			// the repetition runs to the end of the field, as "a/n" does
+			q = append(q, pad(lo, width)+"/"+strconv.Itoa(step))
+		default:
+			for x := lo; x <= hi; x += step {
+				q = append(q, pad(x, width))
+			}
+		}
+	}
+	return strings.Join(q, ",")
+}
+
+// formatValues writes each value of a field as a list
+func formatValues(cf cronfab.CrontabField, min, max int) string {
+	fs := cronfab.NewFieldSet(min, max, cf)
+	var q []string
+	for x := min; x <= max; x++ {
+		if fs.Contains(x) {
+			q = append(q, pad(x, 2))
+		}
+	}
+	return strings.Join(q, ",")
+}
+
+// formatWeekdays writes a day of week field as names, with runs of three or more days as ranges.
+// The field numbers the days from min, which is the number of the first day of the week, as
+// cronfab.NewDayOfWeekField does.
+func formatWeekdays(cf cronfab.CrontabField, min int) string {
+	fs := cronfab.NewFieldSet(min, min+6, cf)
+	var q []string
+	for x := min; x <= min+6; x++ {
+		if !fs.Contains(x) {
+			continue
+		}
+		y := x
+		for y < min+6 && fs.Contains(y+1) {
+			y++
+		}
+		switch {
+		case y-x >= 2:
+			q = append(q, weekdayNames[x%7]+".."+weekdayNames[y%7])
+		case y > x:
+			q = append(q, weekdayNames[x%7], weekdayNames[y%7])
+		default:
+			q = append(q, weekdayNames[x%7])
+		}
+		x = y
+	}
+	return strings.Join(q, ",")
+}
+
+// pad writes v zero padded to width digits
+func pad(v, width int) string {
+	s := strconv.Itoa(v)
+	for len(s) < width {
+		s = "0" + s
+	}
+	return s
+}
@@ -0,0 +1,290 @@
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aalpar/cronfab"
+)
+
+var (
+	ErrEmpty      = errors.New("empty calendar expression")
+	ErrFractional = errors.New("fractional seconds are not supported")
+)
+
+// ErrorSyntax is returned for a calendar expression that can't be parsed
+type ErrorSyntax struct {
+	// Part is the part of the expression that is wrong
+	Part string
+	// Reason says what is wrong with it
+	Reason string
+}
+
+func (e *ErrorSyntax) Error() string {
+	return fmt.Sprintf("invalid calendar expression %q: %s", e.Part, e.Reason)
+}
+
+// shorthands are the expressions systemd accepts in place of a full calendar expression
+var shorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+// weekdays maps the weekday names systemd accepts to day of week values
+var weekdays = map[string]int{
+	"sun": 0, "sunday": 0,
+	"mon": 1, "monday": 1,
+	"tue": 2, "tuesday": 2,
+	"wed": 3, "wednesday": 3,
+	"thu": 4, "thursday": 4,
+	"fri": 5, "friday": 5,
+	"sat": 6, "saturday": 6,
+}
+
+// Parse parses a systemd calendar expression, like "Mon..Fri *-*-* 09:00:00", "*-*-01 00:00",
+// "*-02~01" or "weekly", into a line for Config.  A trailing time zone is returned as a location,
+// or nil if there is none.
+func Parse(s string) (cronfab.CrontabLine, *time.Location, error) {
+	toks := strings.Fields(s)
+	if len(toks) == 0 {
+		return nil, nil, ErrEmpty
+	}
+	if expr, ok := shorthands[strings.ToLower(toks[0])]; ok {
+		toks = append(strings.Fields(expr), toks[1:]...)
+	}
+	ctl := make(cronfab.CrontabLine, len(Config.Fields))
+	for i, f := range Config.Fields {
+		ctl[i] = [][3]int{{f.Min, f.Max, 1}}
+	}
+	ctl[second] = [][3]int{{0, 0, 1}}
+	ctl[minute] = [][3]int{{0, 0, 1}}
+	ctl[hour] = [][3]int{{0, 0, 1}}
+	var loc *time.Location
+	seen := map[string]bool{}
+	for i, tok := range toks {
+		var err error
+		var kind string
+		switch {
+		case strings.Contains(tok, ":"):
+			kind = "time"
+			err = parseTime(tok, ctl)
+		case i == 0 && isWeekdays(tok):
+			kind = "weekdays"
+			ctl[dayOfWeek], err = parseWeekdays(tok)
+		case tok[0] == '*' || (tok[0] >= '0' && tok[0] <= '9'):
+			kind = "date"
+			err = parseDate(tok, ctl)
+		case i == len(toks)-1:
+			kind = "time zone"
+			loc, err = time.LoadLocation(tok)
+			if err != nil {
+				err = &ErrorSyntax{Part: tok, Reason: "unknown time zone"}
+			}
+		default:
+			err = &ErrorSyntax{Part: tok, Reason: "unexpected"}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if seen[kind] {
+			return nil, nil, &ErrorSyntax{Part: tok, Reason: "more than one " + kind}
+		}
+		seen[kind] = true
+	}
+	ctl.Sort()
+	return ctl, loc, nil
+}
+
+// isWeekdays return true if tok starts with a weekday name
+func isWeekdays(tok string) bool {
+	name := strings.ToLower(tok)
+	if i := strings.IndexAny(name, ".,"); i >= 0 {
+		name = name[:i]
+	}
+	_, ok := weekdays[name]
+	return ok
+}
+
+// parseWeekdays parses a list of weekday names and ranges, like "Mon..Fri,Sun"
+func parseWeekdays(tok string) ([][3]int, error) {
+	var q [][3]int
+	for _, part := range strings.Split(tok, ",") {
+		ends := strings.SplitN(part, "..", 2)
+		var vs [2]int
+		for i, name := range ends {
+			v, ok := weekdays[strings.ToLower(name)]
+			if !ok {
+				return nil, &ErrorSyntax{Part: part, Reason: "unknown weekday"}
+			}
+			vs[i] = v
+		}
+		if len(ends) == 1 {
+			q = append(q, [3]int{vs[0], vs[0], 1})
+		} else if vs[0] <= vs[1] {
+			q = append(q, [3]int{vs[0], vs[1], 1})
+		} else {
+			// a range that wraps past Saturday
+			q = append(q, [3]int{vs[0], 6, 1}, [3]int{0, vs[1], 1})
+		}
+	}
+	return q, nil
+}
+
+// parseDate parses "year-month-day" or "month-day", where "~" in place of the last "-" counts the
+// day back from the end of the month
+func parseDate(tok string, ctl cronfab.CrontabLine) error {
+	fromEnd := false
+	rest := tok
+	var day string
+	if i := strings.LastIndexByte(rest, '~'); i >= 0 {
+		fromEnd = true
+		rest, day = rest[:i], rest[i+1:]
+	} else if i := strings.LastIndexByte(rest, '-'); i >= 0 {
+		rest, day = rest[:i], rest[i+1:]
+	} else {
+		return &ErrorSyntax{Part: tok, Reason: "expected month-day"}
+	}
+	parts := strings.Split(rest, "-")
+	if len(parts) > 2 {
+		return &ErrorSyntax{Part: tok, Reason: "expected year-month-day"}
+	}
+	var err error
+	if len(parts) == 2 {
+		ctl[year], err = parseComponent(parts[0], MinYear, MaxYear)
+		if err != nil {
+			return err
+		}
+	}
+	ctl[month], err = parseComponent(parts[len(parts)-1], 1, 12)
+	if err != nil {
+		return err
+	}
+	if !fromEnd {
+		ctl[dayOfMonth], err = parseComponent(day, 1, 31)
+		return err
+	}
+	ctl[daysFromEnd], err = parseFromEnd(day)
+	return err
+}
+
+// parseFromEnd parses the day of a "~" date.  A repetition counts toward the end of the month:
+// "~07/2" is the 7th, 5th, 3rd and last days from the end.
+func parseFromEnd(s string) ([][3]int, error) {
+	var q [][3]int
+	for _, part := range strings.Split(s, ",") {
+		i := strings.IndexByte(part, '/')
+		if i < 0 || strings.Contains(part, "..") || part[0] == '*' {
+			c, err := parseComponent(part, 1, 31)
+			if err != nil {
+				return nil, err
+			}
+			q = append(q, c...)
+			continue
+		}
+		start, err := parseValue(part[:i], 1, 31)
+		if err != nil {
+			return nil, err
+		}
+		step, err := parseValue(part[i+1:], 1, 31)
+		if err != nil {
+			return nil, err
+		}
+		for v := start; v >= 1; v -= step {
+			q = append(q, [3]int{v, v, 1})
+		}
+	}
+	return q, nil
+}
+
+// parseTime parses "hour:minute" or "hour:minute:second"
+func parseTime(tok string, ctl cronfab.CrontabLine) error {
+	parts := strings.Split(tok, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return &ErrorSyntax{Part: tok, Reason: "expected hour:minute[:second]"}
+	}
+	var err error
+	ctl[hour], err = parseComponent(parts[0], 0, 23)
+	if err != nil {
+		return err
+	}
+	ctl[minute], err = parseComponent(parts[1], 0, 59)
+	if err != nil {
+		return err
+	}
+	if len(parts) == 3 {
+		if strings.Contains(parts[2], ".") {
+			return ErrFractional
+		}
+		ctl[second], err = parseComponent(parts[2], 0, 59)
+	}
+	return err
+}
+
+// parseComponent parses a list of values, ranges "a..b" and repetitions "a/n", "a..b/n" or "*/n"
+func parseComponent(s string, min, max int) ([][3]int, error) {
+	var q [][3]int
+	for _, part := range strings.Split(s, ",") {
+		c := [3]int{min, max, 1}
+		rng := part
+		i := strings.IndexByte(part, '/')
+		if i >= 0 {
+			step, err := parseValue(part[i+1:], 1, max)
+			if err != nil {
+				return nil, err
+			}
+			c[2] = step
+			rng = part[:i]
+		}
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, ".."):
+			ends := strings.SplitN(rng, "..", 2)
+			lo, err := parseValue(ends[0], min, max)
+			if err != nil {
+				return nil, err
+			}
+			hi, err := parseValue(ends[1], min, max)
+			if err != nil {
+				return nil, err
+			}
+			if lo > hi {
+				return nil, &ErrorSyntax{Part: part, Reason: "range start after end"}
+			}
+			c[0], c[1] = lo, hi
+		default:
+			v, err := parseValue(rng, min, max)
+			if err != nil {
+				return nil, err
+			}
+			c[0] = v
+			if i < 0 {
+				// a single value; with a repetition, even "/1", it is a start that runs to max
+				c[1] = v
+			}
+		}
+		q = append(q, c)
+	}
+	return q, nil
+}
+
+// parseValue parses a decimal value in [min, max]
+func parseValue(s string, min, max int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, &ErrorSyntax{Part: s, Reason: "expected a number"}
+	}
+	if v < min || v > max {
+		return 0, &ErrorSyntax{Part: s, Reason: fmt.Sprintf("out of range %d-%d", min, max)}
+	}
+	return v, nil
+}
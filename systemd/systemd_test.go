@@ -0,0 +1,290 @@
+package systemd
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aalpar/cronfab"
+)
+
+func TestParse_Next(t *testing.T) {
+	for _, tc := range []struct {
+		expr  string
+		start time.Time
+		want  []time.Time
+	}{
+		{
+			"Mon..Fri *-*-* 09:00:00",
+			time.Date(2024, 3, 16, 12, 0, 0, 0, time.UTC), // a Saturday
+			[]time.Time{
+				time.Date(2024, 3, 18, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 19, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"*-*-01 00:00",
+			time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+			[]time.Time{
+				time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"*-02~01",
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			[]time.Time{
+				time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"*-*~07/2 12:00",
+			time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			[]time.Time{
+				time.Date(2024, 4, 24, 12, 0, 0, 0, time.UTC),
+				time.Date(2024, 4, 26, 12, 0, 0, 0, time.UTC),
+				time.Date(2024, 4, 28, 12, 0, 0, 0, time.UTC),
+				time.Date(2024, 4, 30, 12, 0, 0, 0, time.UTC),
+				time.Date(2024, 5, 25, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"weekly",
+			time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC),
+			[]time.Time{
+				time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"quarterly",
+			time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			[]time.Time{
+				time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"*:0/15",
+			time.Date(2024, 3, 1, 10, 7, 0, 0, time.UTC),
+			[]time.Time{
+				time.Date(2024, 3, 1, 10, 15, 0, 0, time.UTC),
+				time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			"Sat..Mon 2025-*-* 08:30:15",
+			time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+			[]time.Time{
+				time.Date(2025, 1, 4, 8, 30, 15, 0, time.UTC),
+				time.Date(2025, 1, 5, 8, 30, 15, 0, time.UTC),
+				time.Date(2025, 1, 6, 8, 30, 15, 0, time.UTC),
+				time.Date(2025, 1, 11, 8, 30, 15, 0, time.UTC),
+			},
+		},
+	} {
+		ctl, loc, err := Parse(tc.expr)
+		if err != nil {
+			t.Errorf("%q: %v", tc.expr, err)
+			continue
+		}
+		if loc != nil {
+			t.Errorf("%q: unexpected location %v", tc.expr, loc)
+		}
+		n := tc.start
+		for _, want := range tc.want {
+			n, err = Config.Next(ctl, n)
+			if err != nil {
+				t.Fatalf("%q: %v", tc.expr, err)
+			}
+			if !n.Equal(want) {
+				t.Errorf("%q: expected %v, got %v", tc.expr, want, n)
+				break
+			}
+		}
+	}
+}
+
+func TestParse_Repetition(t *testing.T) {
+	for _, tc := range []struct {
+		expr  string
+		field int
+		want  cronfab.CrontabField
+	}{
+		{"*:5/1:00", minute, cronfab.CrontabField{{5, 59, 1}}},
+		{"*:5:00", minute, cronfab.CrontabField{{5, 5, 1}}},
+		{"*:5/20:00", minute, cronfab.CrontabField{{5, 59, 20}}},
+		{"20/1:00", hour, cronfab.CrontabField{{20, 23, 1}}},
+		{"*-*-28/1", dayOfMonth, cronfab.CrontabField{{28, 31, 1}}},
+		{"2030/1-01-01", year, cronfab.CrontabField{{2030, MaxYear, 1}}},
+	} {
+		ctl, _, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.expr, err)
+		}
+		if got := ctl.GetField(tc.field); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: expected %v, got %v", tc.expr, tc.want, got)
+		}
+	}
+}
+
+func TestParse_Location(t *testing.T) {
+	ctl, loc, err := Parse("daily Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc == nil || loc.String() != "Europe/Berlin" {
+		t.Fatalf("expected Europe/Berlin, got %v", loc)
+	}
+	n, err := Config.Next(ctl, time.Date(2024, 3, 1, 12, 0, 0, 0, loc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 2, 0, 0, 0, 0, loc); !n.Equal(want) {
+		t.Errorf("expected %v, got %v", want, n)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"Funday *-*-*",
+		"*-13-01",
+		"*-*-* 24:00",
+		"*-*-* 12:00 13:00",
+		"1969-01-01",
+		"*-*-* 12:00:00.5",
+		"*-*-* 12:00 Nowhere/Special",
+		"*-*-05..01",
+		"2024-01-01-01",
+	} {
+		if _, _, err := Parse(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+	if _, _, err := Parse("*-*-* 1:2:3.25"); !errors.Is(err, ErrFractional) {
+		t.Errorf("expected %v, got %v", ErrFractional, err)
+	}
+}
+
+func TestSatisfiable(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		sat  bool
+	}{
+		{"2030-01-01 00:00:00", true},
+		{"2024,2100-02-29", true},
+		{"2199-12-31 23:59:59", true},
+		{"2025-02-29", false},
+		{"2101..2103-02-29", false},
+	} {
+		ctl, _, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = Config.Satisfiable(ctl)
+		if tc.sat && err != nil {
+			t.Errorf("%q: %v", tc.expr, err)
+		}
+		if !tc.sat && !errors.Is(err, cronfab.ErrUnsatisfiable) {
+			t.Errorf("%q: expected %v, got %v", tc.expr, cronfab.ErrUnsatisfiable, err)
+		}
+	}
+}
+
+func TestFormat_RoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		want string
+	}{
+		{"Mon..Fri *-*-* 09:00:00", "Mon..Fri *-*-* 09:00:00"},
+		{"*-*-01 00:00", "*-*-01 00:00:00"},
+		{"*-02~01", "*-02~01 00:00:00"},
+		{"*-*~07/2", "*-*~01,03,05,07 00:00:00"},
+		{"weekly", "Mon *-*-* 00:00:00"},
+		{"quarterly", "*-01,04,07,10-01 00:00:00"},
+		{"*:0/15", "*-*-* *:00/15:00"},
+		{"Sat,Sun 2025-*-* 08..17:30", "Sun,Sat 2025-*-* 08..17:30:00"},
+		{"*-*-* 00:00:00/20", "*-*-* 00:00:00/20"},
+	} {
+		ctl, _, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.expr, err)
+		}
+		got, err := Format(Config, ctl)
+		if err != nil {
+			t.Errorf("%q: %v", tc.expr, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.expr, tc.want, got)
+		}
+		again, _, err := Parse(got)
+		if err != nil {
+			t.Fatalf("%q: %v", got, err)
+		}
+		if again.String() != ctl.String() {
+			t.Errorf("%q: expected %v, got %v", got, ctl, again)
+		}
+	}
+}
+
+func TestFormat_Crontab(t *testing.T) {
+	for _, tc := range []struct {
+		cc   *cronfab.CrontabConfig
+		expr string
+		want string
+	}{
+		{cronfab.DefaultCrontabConfig, "0 9 * * 1-5", "Mon..Fri *-*-* 09:00:00"},
+		{cronfab.DefaultCrontabConfig, "*/10 * 1,15 * *", "*-*-01,15 *:00/10:00"},
+		{cronfab.DefaultCrontabConfig, "30 4 * jan *", "*-01-* 04:30:00"},
+		{cronfab.DefaultCrontabConfig, "0 0 * * */2", "Sun,Tue,Thu,Sat *-*-* 00:00:00"},
+		{cronfab.DefaultCrontabConfig, "0 0-12/4 * * *", "*-*-* 00,04,08,12:00:00"},
+		{cronfab.SecondCrontabConfig, "15 0 12 * * * *", "*-*-* 12:00:15"},
+		{cronfab.DefaultCrontabConfig.WithFirstDay(time.Monday), "0 9 * * sat-sun", "Sat,Sun *-*-* 09:00:00"},
+		{cronfab.DefaultCrontabConfig.WithFirstDay(time.Monday), "0 9 * * fri-sun", "Fri..Sun *-*-* 09:00:00"},
+	} {
+		ctl, err := tc.cc.ParseCronTab(tc.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Format(tc.cc, ctl)
+		if err != nil {
+			t.Errorf("%q: %v", tc.expr, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.expr, tc.want, got)
+		}
+	}
+}
+
+func TestFormat_Untranslatable(t *testing.T) {
+	ctl, err := cronfab.SecondCrontabConfig.ParseCronTab("0 0 0 * 2-3 * 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Format(cronfab.SecondCrontabConfig, ctl)
+	var eu *ErrorUntranslatable
+	if !errors.As(err, &eu) || !errors.Is(err, ErrUntranslatable) {
+		t.Fatalf("expected an untranslatable error, got %v", err)
+	}
+	if len(eu.Parts) != 1 || !strings.HasPrefix(eu.Parts[0], "week of month 2-3") {
+		t.Errorf("unexpected parts %q", eu.Parts)
+	}
+
+	ctl, _, err = Parse("*-*-01 00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctl[daysFromEnd] = [][3]int{{1, 1, 1}}
+	if _, err := Format(Config, ctl); !errors.Is(err, ErrUntranslatable) {
+		t.Errorf("expected %v, got %v", ErrUntranslatable, err)
+	}
+	if _, err := Format(Config, ctl[:3]); !errors.Is(err, ErrFieldCount) {
+		t.Errorf("expected %v, got %v", ErrFieldCount, err)
+	}
+}
@@ -0,0 +1,72 @@
+package cronfab
+
+import (
+	"sync"
+	"time"
+)
+
+// Ticker delivers the fire times of a schedule on a channel as the clock reaches them.
+type Ticker struct {
+	// C receives each fire time when it is reached.  Like time.Ticker, fire times are dropped
+	// while the previous one has not been received.  C is closed when the ticker stops.
+	C <-chan time.Time
+
+	c        chan time.Time
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+	err      error
+}
+
+// NewTicker return a ticker for the fire times of s after the clock's current time.
+func NewTicker(s Schedule, clock Clock) *Ticker {
+	c := make(chan time.Time, 1)
+	tk := &Ticker{
+		C:    c,
+		c:    c,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go tk.run(s, clock)
+	return tk
+}
+
+func (tk *Ticker) run(s Schedule, clock Clock) {
+	defer close(tk.done)
+	defer close(tk.c)
+	t := clock.Now()
+	for {
+		next, err := s.Next(t)
+		if err != nil {
+			tk.err = err
+			return
+		}
+		timer := clock.NewTimer(next.Sub(clock.Now()))
+		select {
+		case <-timer.C():
+		case <-tk.stop:
+			timer.Stop()
+			return
+		}
+		select {
+		case tk.c <- next:
+		default:
+		}
+		t = next
+	}
+}
+
+// Stop stops the ticker and closes C.  It is safe to call more than once.
+func (tk *Ticker) Stop() {
+	tk.stopOnce.Do(func() {
+		close(tk.stop)
+	})
+	<-tk.done
+}
+
+// Err return the error that stopped the ticker, if the schedule failed.
+// It is only meaningful once C is closed.
+func (tk *Ticker) Err() error {
+	<-tk.done
+	return tk.err
+}
@@ -0,0 +1,121 @@
+package cronfab_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aalpar/cronfab"
+	"github.com/aalpar/cronfab/cronfabtest"
+)
+
+var _ cronfab.Schedule = (*cronfab.CompiledLine)(nil)
+
+func compile(t *testing.T, expr string) *cronfab.CompiledLine {
+	t.Helper()
+	cc := cronfab.DefaultCrontabConfig
+	ctl, err := cc.ParseCronTab(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl, err := cc.Compile(ctl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cl
+}
+
+func TestTicker_FakeClockMonth(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	clock := cronfabtest.NewFakeClock(start)
+	tk := cronfab.NewTicker(compile(t, "*/15 * * * *"), clock)
+	defer tk.Stop()
+
+	want := start
+	q := 0
+	for want.Before(end) {
+		want = want.Add(15 * time.Minute)
+		clock.BlockUntil(1)
+		clock.Advance(15 * time.Minute)
+		got := <-tk.C
+		if !got.Equal(want) {
+			t.Fatalf("tick %d: expected %v, got %v", q, want, got)
+		}
+		q++
+	}
+	if q != 31*24*4 {
+		t.Errorf("expected %d ticks, got %d", 31*24*4, q)
+	}
+}
+
+func TestTicker_SkipsAhead(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	clock := cronfabtest.NewFakeClock(start)
+	tk := cronfab.NewTicker(compile(t, "0 * * * *"), clock)
+	defer tk.Stop()
+
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Minute)
+	select {
+	case got := <-tk.C:
+		t.Fatalf("unexpected tick at %v", got)
+	default:
+	}
+	clock.Advance(30 * time.Minute)
+	if got, want := <-tk.C, start.Add(time.Hour); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestTicker_Stop(t *testing.T) {
+	clock := cronfabtest.NewFakeClock(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	tk := cronfab.NewTicker(compile(t, "* * * * *"), clock)
+	clock.BlockUntil(1)
+	tk.Stop()
+	tk.Stop()
+	if _, ok := <-tk.C; ok {
+		t.Errorf("expected C to be closed")
+	}
+	if err := tk.Err(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if clock.Timers() != 0 {
+		t.Errorf("expected the timer to be stopped, %d pending", clock.Timers())
+	}
+}
+
+type failingSchedule struct {
+	err error
+}
+
+func (s failingSchedule) Next(t time.Time) (time.Time, error) {
+	return time.Time{}, s.err
+}
+
+func TestTicker_Err(t *testing.T) {
+	errBoom := errors.New("boom")
+	tk := cronfab.NewTicker(failingSchedule{errBoom}, cronfab.RealClock{})
+	if _, ok := <-tk.C; ok {
+		t.Errorf("expected C to be closed")
+	}
+	if err := tk.Err(); !errors.Is(err, errBoom) {
+		t.Errorf("expected %v, got %v", errBoom, err)
+	}
+}
+
+type everySchedule time.Duration
+
+func (s everySchedule) Next(t time.Time) (time.Time, error) {
+	return t.Add(time.Duration(s)), nil
+}
+
+func TestTicker_RealClock(t *testing.T) {
+	tk := cronfab.NewTicker(everySchedule(20*time.Millisecond), cronfab.RealClock{})
+	defer tk.Stop()
+	select {
+	case <-tk.C:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a tick")
+	}
+}
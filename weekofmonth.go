@@ -0,0 +1,73 @@
+package cronfab
+
+import (
+	"time"
+)
+
+// NewWeekOfMonthField return a field of the row of the month's calendar, from 1 to 6, with rows
+// starting on first.  The first row holds the first of the month and may be short.
+func NewWeekOfMonthField(first time.Weekday) FieldConfig {
+	return FieldConfig{
+		Unit: WeekOfMonth{FirstDay: first},
+		Name: "week of month",
+		Min:  1,
+		Max:  6,
+		GetIndex: func(t time.Time) int {
+			// the first of the month is this many days into the first row
+			lead := daysSince(t.AddDate(0, 0, 1-t.Day()).Weekday(), first)
+			return (t.Day()-1+lead)/7 + 1
+		},
+	}
+}
+
+// NewOrdinalWeekOfMonthField return a field of the 7 day block of the month, from 1 to 5: days 1
+// to 7 are week 1, days 8 to 14 week 2, and so on.  Week n holds the nth occurrence of every
+// weekday, so "3 * tue" is the third Tuesday of the month.
+func NewOrdinalWeekOfMonthField() FieldConfig {
+	return FieldConfig{
+		Unit: WeekOfMonth{Ordinal: true},
+		Name: "week of month",
+		Min:  1,
+		Max:  5,
+		GetIndex: func(t time.Time) int {
+			return (t.Day()-1)/7 + 1
+		},
+	}
+}
+
+// daysSince return how many days w is after the weekday first
+func daysSince(w, first time.Weekday) int {
+	return (int(w) - int(first) + 7) % 7
+}
+
+// daysIn return the number of days in month m of year y
+func daysIn(y int, m time.Month, loc *time.Location) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, loc).Day()
+}
+
+// addOrdinalWeeks moves t by n 7 day blocks of the month, keeping its day within the block and its
+// time of day
+func addOrdinalWeeks(t time.Time, n int) time.Time {
+	loc := t.Location()
+	y, m, d := t.Date()
+	off, b := (d-1)%7, (d-1)/7
+	for ; n > 0; n-- {
+		b++
+		if 7*b >= daysIn(y, m, loc) {
+			b = 0
+			y, m, _ = time.Date(y, m+1, 1, 0, 0, 0, 0, loc).Date()
+		}
+	}
+	for ; n < 0; n++ {
+		b--
+		if b < 0 {
+			y, m, _ = time.Date(y, m-1, 1, 0, 0, 0, 0, loc).Date()
+			b = (daysIn(y, m, loc) - 1) / 7
+		}
+	}
+	d = 7*b + 1 + off
+	if last := daysIn(y, m, loc); d > last {
+		d = last
+	}
+	return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
@@ -0,0 +1,153 @@
+package cronfab
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewWeekOfMonthField(t *testing.T) {
+	sunday := NewWeekOfMonthField(time.Sunday)
+	monday := NewWeekOfMonthField(time.Monday)
+	ordinal := NewOrdinalWeekOfMonthField()
+	day := func(m time.Month, d int) time.Time {
+		return time.Date(2024, m, d, 12, 0, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		f    FieldConfig
+		t    time.Time
+		want int
+	}{
+		// September 2024 starts on a Sunday
+		{sunday, day(9, 7), 1},
+		{sunday, day(9, 8), 2},
+		{sunday, day(9, 30), 5},
+		{monday, day(9, 1), 1},
+		{monday, day(9, 2), 2},
+		{monday, day(9, 30), 6},
+		// June 2024 starts on a Saturday and has 30 days
+		{sunday, day(6, 1), 1},
+		{sunday, day(6, 30), 6},
+		{monday, day(6, 30), 5},
+		{ordinal, day(6, 7), 1},
+		{ordinal, day(6, 8), 2},
+		{ordinal, day(6, 30), 5},
+	} {
+		if got := tc.f.GetIndex(tc.t); got != tc.want {
+			t.Errorf("%s %v: expected %d, got %d", tc.f.Unit, tc.t, tc.want, got)
+		}
+	}
+}
+
+func TestWeekOfMonth_Ordinal(t *testing.T) {
+	u := WeekOfMonth{Ordinal: true}
+	at := func(m time.Month, d int) time.Time {
+		return time.Date(2025, m, d, 9, 30, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		t    time.Time
+		n    int
+		want time.Time
+	}{
+		{at(1, 26), 1, at(1, 31)},
+		{at(1, 30), 1, at(2, 2)},
+		{at(2, 24), 1, at(3, 3)},
+		{at(3, 1), -1, at(2, 22)},
+		{at(3, 31), -2, at(3, 17)},
+		{at(1, 3), 10, at(3, 10)},
+	} {
+		if got := u.Add(tc.t, tc.n); !got.Equal(tc.want) {
+			t.Errorf("Add(%v, %d): expected %v, got %v", tc.t, tc.n, tc.want, got)
+		}
+	}
+	if got, want := u.Trunc(at(1, 31)), time.Date(2025, 1, 29, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got, want := (WeekOfMonth{FirstDay: time.Monday}).Trunc(at(3, 2)), time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestNext_WeekOfMonth(t *testing.T) {
+	withWeek := func(f FieldConfig) *CrontabConfig {
+		fields := append([]FieldConfig{}, SecondCrontabConfig.Fields...)
+		fields[4] = f
+		return MustCrontabConfig(fields)
+	}
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		cc    *CrontabConfig
+		line  string
+		start time.Time
+		want  []time.Time
+	}{
+		// the third Tuesday
+		{withWeek(NewOrdinalWeekOfMonthField()), "0 0 0 * 3 * tue", at(2025, 1, 1), []time.Time{at(2025, 1, 21), at(2025, 2, 18), at(2025, 3, 18)}},
+		// the Monday of the second row starting Monday: September 2024 starts on a Sunday
+		{withWeek(NewWeekOfMonthField(time.Monday)), "0 0 0 * 2 * mon", at(2024, 8, 20), []time.Time{at(2024, 9, 2), at(2024, 10, 7)}},
+		// the Saturday of the first row starting Sunday: September 2024 is the row of the 1st to the 7th
+		{withWeek(NewWeekOfMonthField(time.Sunday)), "0 0 0 * 1 * sat", at(2024, 8, 20), []time.Time{at(2024, 9, 7), at(2024, 10, 5)}},
+		// only months that start on a Friday or Saturday and are long enough have a sixth row
+		{withWeek(NewWeekOfMonthField(time.Sunday)), "0 0 0 * 6 * *", at(2024, 6, 1), []time.Time{at(2024, 6, 30), at(2025, 3, 30), at(2025, 3, 31)}},
+	} {
+		cl, err := tc.cc.Compile(mustLine(t, tc.cc, tc.line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := collect(t, cl, tc.start, len(tc.want))
+		for i := range tc.want {
+			if !got[i].Equal(tc.want[i]) {
+				t.Errorf("%q %d: expected %v, got %v", tc.line, i, tc.want[i], got[i])
+			}
+		}
+	}
+}
+
+// SecondCrontabConfig keeps the week of month numbering it has always had, in which a month that
+// starts on a Sunday has no week 1
+func TestSecondCrontabConfig_WeekOfMonth(t *testing.T) {
+	f := SecondCrontabConfig.Fields[4]
+	day := func(m time.Month, d int) time.Time {
+		return time.Date(2024, m, d, 12, 0, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		t    time.Time
+		want int
+	}{
+		// September 2024 starts on a Sunday
+		{day(9, 1), 2},
+		{day(9, 7), 2},
+		{day(9, 8), 3},
+		{day(9, 30), 6},
+		// June 2024 starts on a Saturday
+		{day(6, 1), 1},
+		{day(6, 2), 2},
+		{day(6, 30), 6},
+	} {
+		if got := f.GetIndex(tc.t); got != tc.want {
+			t.Errorf("%v: expected %d, got %d", tc.t, tc.want, got)
+		}
+	}
+	if f.Min != 1 || f.Max != 5 {
+		t.Errorf("expected 1-5, got %d-%d", f.Min, f.Max)
+	}
+
+	cl, err := SecondCrontabConfig.Compile(mustLine(t, SecondCrontabConfig, "0 0 0 * 2 * sat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(m time.Month, d int) time.Time {
+		return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	want := []time.Time{at(9, 7), at(10, 12)}
+	got := collect(t, cl, at(8, 20), len(want))
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("%d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+	if same := SecondCrontabConfig.WithFirstDay(time.Sunday); same.Fields[4].GetIndex(day(9, 1)) != 2 {
+		t.Error("WithFirstDay(time.Sunday) replaced the week of month field")
+	}
+}